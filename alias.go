@@ -0,0 +1,192 @@
+package elastic_wrapper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+)
+
+// IndexAliasesResponse index name -> the aliases of the index
+type IndexAliasesResponse map[string]struct {
+	Aliases map[string]types.AliasDefinition `json:"aliases"`
+}
+
+func (i *IndexAliasesResponse) FromJSON(bytes []byte) error {
+	return FromJSONImplDefault(bytes, i)
+}
+
+// NotFoundFromJSON the 404 of get alias has the aliases which exist next to the error and the status, e.g.
+// {"error":"alias [missing] missing","status":404,"logs":{"aliases":{"logs_write":{}}}}
+func (i *IndexAliasesResponse) NotFoundFromJSON(bytes []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(bytes, &raw); err != nil {
+		return ErrNotFound
+	}
+	// the error is an object if the index does not exist
+	var reason string
+	if err := json.Unmarshal(raw["error"], &reason); err != nil {
+		reason = string(raw["error"])
+	}
+	delete(raw, "error")
+	delete(raw, "status")
+
+	if len(raw) > 0 {
+		existing, err := json.Marshal(raw)
+		if err != nil {
+			return err
+		}
+		if err := i.FromJSON(existing); err != nil {
+			return err
+		}
+	}
+	return fmt.Errorf("%s: %w", reason, ErrNotFound)
+}
+
+// AliasAction one action of UpdateAliases, built by AliasAdd, AliasRemove or AliasRemoveIndex
+type AliasAction map[string]any
+
+type aliasAddAction struct {
+	Index string `json:"index"`
+	Alias string `json:"alias"`
+	*types.AliasDefinition
+}
+
+// AliasAdd add the alias to the index, def is optional: filter, routing, index_routing, search_routing,
+// is_write_index and is_hidden. Adding an existing alias again replaces its definition
+func AliasAdd(index, alias string, def *types.AliasDefinition) AliasAction {
+	return AliasAction{"add": aliasAddAction{Index: index, Alias: alias, AliasDefinition: def}}
+}
+
+// AliasRemove remove the alias from the index, wildcards are supported
+func AliasRemove(index, alias string) AliasAction {
+	return AliasAction{"remove": map[string]string{"index": index, "alias": alias}}
+}
+
+// AliasRemoveIndex delete the index, in the same atomic operation as the other alias actions
+func AliasRemoveIndex(index string) AliasAction {
+	return AliasAction{"remove_index": map[string]string{"index": index}}
+}
+
+// UpdateAliases perform the alias actions atomically
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/indices-aliases.html
+func (es *ElasticsearchEx) UpdateAliases(ctx context.Context, actions ...AliasAction) error {
+	body, err := json.Marshal(map[string]any{"actions": actions})
+	if err != nil {
+		return err
+	}
+	var rsp AcknowledgedResponse
+	return doGetResponse[ErrGeneric](ctx, es.Indices.UpdateAliases().Raw(body), &rsp)
+}
+
+// AddAlias add the alias to the index, def is optional, see AliasAdd
+func (es *ElasticsearchEx) AddAlias(ctx context.Context, index, alias string, def *types.AliasDefinition) error {
+	return es.UpdateAliases(ctx, AliasAdd(index, alias, def))
+}
+
+// RemoveAlias remove the alias from the index, ErrNotFound if the alias does not exist
+func (es *ElasticsearchEx) RemoveAlias(ctx context.Context, index, alias string) error {
+	return es.UpdateAliases(ctx, AliasRemove(index, alias))
+}
+
+// SwapAlias atomically move the alias from the index `from` to the index `to`, def is optional, see AliasAdd.
+// An empty `from` moves the alias away from all the indices it currently points to
+func (es *ElasticsearchEx) SwapAlias(ctx context.Context, alias, from, to string, def *types.AliasDefinition) error {
+	var froms []string
+	if from != "" {
+		froms = []string{from}
+	} else {
+		indices, err := es.GetAliases(ctx, "", alias)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+		for index := range indices {
+			if index != to {
+				froms = append(froms, index)
+			}
+		}
+		sort.Strings(froms)
+	}
+	actions := make([]AliasAction, 0, len(froms)+1)
+	for _, index := range froms {
+		actions = append(actions, AliasRemove(index, alias))
+	}
+	actions = append(actions, AliasAdd(to, alias, def))
+	return es.UpdateAliases(ctx, actions...)
+}
+
+// GetAliases the aliases of the index, or of all indices if index is empty,
+// only the listed aliases are returned if any, wildcards are supported.
+// If some of the listed aliases do not exist, the existing ones are returned together with an error wrapping ErrNotFound
+func (es *ElasticsearchEx) GetAliases(ctx context.Context, index string, aliases ...string) (IndexAliasesResponse, error) {
+	req := es.Indices.GetAlias()
+	if index != "" {
+		req.Index(index)
+	}
+	if len(aliases) > 0 {
+		req.Name(strings.Join(aliases, ","))
+	}
+	var rsp IndexAliasesResponse
+	err := doGetResponse[ErrGeneric](ctx, req, &rsp)
+	return rsp, err
+}
+
+// ResolvedAlias the indices behind an alias
+type ResolvedAlias struct {
+	Name       string
+	Indices    []string // sorted
+	WriteIndex string   // the index which receives the writes through the alias, empty if writes are rejected
+	IsAlias    bool     // false if Name is a concrete index
+}
+
+// ResolveAlias the indices and the write index behind the alias.
+// A concrete index name resolves to itself, so the result is usable wherever an index name is accepted
+func (es *ElasticsearchEx) ResolveAlias(ctx context.Context, name string) (*ResolvedAlias, error) {
+	rsp, err := es.GetAliases(ctx, "", name)
+	if errors.Is(err, ErrNotFound) {
+		exists, err := es.IndexExists(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, fmt.Errorf("alias or index %s: %w", name, ErrNotFound)
+		}
+		return &ResolvedAlias{Name: name, Indices: []string{name}, WriteIndex: name}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := &ResolvedAlias{Name: name, IsAlias: true}
+	for index, aliases := range rsp {
+		resolved.Indices = append(resolved.Indices, index)
+		if def, ok := aliases.Aliases[name]; ok && def.IsWriteIndex != nil && *def.IsWriteIndex {
+			resolved.WriteIndex = index
+		}
+	}
+	sort.Strings(resolved.Indices)
+	// without an explicit write index, writes are only accepted if the alias points to a single index
+	if resolved.WriteIndex == "" && len(resolved.Indices) == 1 {
+		def := rsp[resolved.Indices[0]].Aliases[name]
+		if def.IsWriteIndex == nil {
+			resolved.WriteIndex = resolved.Indices[0]
+		}
+	}
+	return resolved, nil
+}
+
+// aliasIndices the sorted names of the indices behind the alias, the alias must exist
+func (es *ElasticsearchEx) aliasIndices(ctx context.Context, alias string) ([]string, error) {
+	resolved, err := es.ResolveAlias(ctx, alias)
+	if err != nil {
+		return nil, err
+	}
+	if !resolved.IsAlias {
+		return nil, fmt.Errorf("%s is an index, not an alias", alias)
+	}
+	return resolved.Indices, nil
+}
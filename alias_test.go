@@ -0,0 +1,139 @@
+package elastic_wrapper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestAliasActionJSON(t *testing.T) {
+	routing := "tenant-a"
+	actions := []AliasAction{
+		AliasRemove("orders_v1", "orders"),
+		AliasAdd("orders_v2", "orders", &types.AliasDefinition{Routing: &routing, IsWriteIndex: proto.Bool(true)}),
+		AliasAdd("orders_v2", "orders_all", nil),
+		AliasRemoveIndex("orders_v0"),
+	}
+	got, err := json.Marshal(actions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := `[{"remove":{"alias":"orders","index":"orders_v1"}},` +
+		`{"add":{"index":"orders_v2","alias":"orders","is_write_index":true,"routing":"tenant-a"}},` +
+		`{"add":{"index":"orders_v2","alias":"orders_all"}},` +
+		`{"remove_index":{"index":"orders_v0"}}]`
+	if string(got) != expect {
+		t.Fatalf("expect %s\ngot    %s", expect, got)
+	}
+}
+
+func TestIndexAliasesResponseNotFound(t *testing.T) {
+	var rsp IndexAliasesResponse
+	err := rsp.NotFoundFromJSON([]byte(`{"error":"alias [missing] missing","status":404,"logs":{"aliases":{"logs_write":{}}}}`))
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expect ErrNotFound, err=%v", err)
+	}
+	if _, ok := rsp["logs"].Aliases["logs_write"]; !ok || len(rsp) != 1 {
+		t.Fatalf("expect the existing alias, rsp=%+v", rsp)
+	}
+
+	rsp = nil
+	err = rsp.NotFoundFromJSON([]byte(`{"error":"alias [missing] missing","status":404}`))
+	if !errors.Is(err, ErrNotFound) || len(rsp) != 0 {
+		t.Fatalf("expect no alias and ErrNotFound, rsp=%+v err=%v", rsp, err)
+	}
+}
+
+func TestAliasManagement(t *testing.T) {
+	es := newClient(t)
+	ctx := context.Background()
+
+	alias := "test_alias"
+	index1, index2 := "test_alias_1", "test_alias_2"
+
+	t.Cleanup(func() {
+		for _, index := range []string{index1, index2} {
+			if _, err := es.IndexDelete(context.Background(), index); err != nil {
+				t.Fatalf("delete index %s failed, err=%v", index, err)
+			}
+		}
+	})
+	for _, index := range []string{index1, index2} {
+		mapping := &types.TypeMapping{Properties: map[string]types.Property{"tenant": types.NewKeywordProperty()}}
+		if _, err := es.IndexCreateSimple(ctx, index, mapping); err != nil {
+			t.Fatalf("create index %s failed, err=%v", index, err)
+		}
+	}
+
+	// a concrete index resolves to itself
+	resolved, err := es.ResolveAlias(ctx, index1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolved.IsAlias || resolved.WriteIndex != index1 {
+		t.Fatalf("unexpected resolved=%+v", resolved)
+	}
+
+	// a filtered alias with routing
+	tenantAlias := alias + "_tenant_a"
+	routing := "tenant-a"
+	err = es.AddAlias(ctx, index1, tenantAlias, &types.AliasDefinition{
+		Filter:  &types.Query{Term: map[string]types.TermQuery{"tenant": {Value: "tenant-a"}}},
+		Routing: &routing,
+	})
+	if err != nil {
+		t.Fatalf("add filtered alias failed, err=%v", err)
+	}
+	aliases, err := es.GetAliases(ctx, index1, tenantAlias)
+	if err != nil {
+		t.Fatal(err)
+	}
+	def := aliases[index1].Aliases[tenantAlias]
+	if def.Filter == nil || def.IndexRouting == nil || *def.IndexRouting != routing {
+		t.Fatalf("unexpected alias definition=%+v", def)
+	}
+	if _, err := es.DocIndexSimple(ctx, tenantAlias, "1", map[string]any{"tenant": "tenant-a"}); err != nil {
+		t.Fatalf("index through the alias failed, err=%v", err)
+	}
+	if _, err := DocGetSource[map[string]any](ctx, es, index1, "1", WithRouting(routing)); err != nil {
+		t.Fatalf("the document should be routed by the alias, err=%v", err)
+	}
+
+	// an alias over two indices with an explicit write index
+	if err := es.UpdateAliases(ctx,
+		AliasAdd(index1, alias, &types.AliasDefinition{IsWriteIndex: proto.Bool(true)}),
+		AliasAdd(index2, alias, nil),
+	); err != nil {
+		t.Fatalf("update aliases failed, err=%v", err)
+	}
+	resolved, err = es.ResolveAlias(ctx, alias)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resolved.IsAlias || len(resolved.Indices) != 2 || resolved.WriteIndex != index1 {
+		t.Fatalf("unexpected resolved=%+v", resolved)
+	}
+
+	// move the alias away from both indices
+	if err := es.SwapAlias(ctx, alias, "", index2, nil); err != nil {
+		t.Fatalf("swap alias failed, err=%v", err)
+	}
+	resolved, err = es.ResolveAlias(ctx, alias)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resolved.Indices) != 1 || resolved.WriteIndex != index2 {
+		t.Fatalf("unexpected resolved=%+v", resolved)
+	}
+
+	if err := es.RemoveAlias(ctx, index2, alias); err != nil {
+		t.Fatalf("remove alias failed, err=%v", err)
+	}
+	if _, err := es.GetAliases(ctx, "", alias); err == nil {
+		t.Fatalf("the alias should be removed")
+	}
+}
@@ -49,6 +49,12 @@ func (r *ResponseGeneric) FromJSON(buf []byte) error {
 	return json.Unmarshal(buf, r)
 }
 
+// NotFoundFromJSON is implemented by the responses with a partial result in the body of a 404,
+// the error returned wraps ErrNotFound
+type NotFoundFromJSON interface {
+	NotFoundFromJSON(bytes []byte) error
+}
+
 func doGetResponse[E error](ctx context.Context, req HttpRequest, rsp FromJSON) error {
 	res, err := req.Do(ctx)
 	defer bodyClose(res)
@@ -65,6 +71,9 @@ func doGetResponse[E error](ctx context.Context, req HttpRequest, rsp FromJSON)
 
 	if !isSuccess(res) {
 		if res.StatusCode == http.StatusNotFound {
+			if partial, ok := rsp.(NotFoundFromJSON); ok {
+				return partial.NotFoundFromJSON(body)
+			}
 			return ErrNotFound
 		}
 		if res.StatusCode == http.StatusConflict {
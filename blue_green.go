@@ -0,0 +1,290 @@
+package elastic_wrapper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/indices/create"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/versiontype"
+)
+
+const (
+	BlueGreenPhaseReindex = "reindex"
+	BlueGreenPhaseCatchUp = "catch_up"
+)
+
+// blueGreenRollbackTimeout bounds the rollback of a failed BlueGreenReindex, which does not use the caller's ctx
+const blueGreenRollbackTimeout = time.Minute
+
+var versionedIndexRe = regexp.MustCompile(`^(.+)_v(\d+)$`)
+
+// BlueGreenOptions the optional settings of BlueGreenReindex, the zero value works
+type BlueGreenOptions struct {
+	NewIndex string               // the name of the new index, <alias>_v<N+1> by default, e.g. orders_v6 -> orders_v7
+	Settings *types.IndexSettings // the settings of the new index, e.g. the analysis with the new analyzers
+
+	Script            *types.Script // transform the documents while reindexing
+	Pipeline          string        // the ingest pipeline of the new index
+	RequestsPerSecond float64       // throttle the reindex, 0 means no throttling
+
+	// CatchUpQuery limit the catch-up pass to the recently written documents, e.g. a range query on updated_at,
+	// nil means a full pass which only copies the documents updated since the first pass
+	CatchUpQuery *types.Query
+	// AllowWrites keep the old index writable during the catch-up pass. By default it gets a write block,
+	// so writes through the alias fail until the alias is moved, but none is lost. With AllowWrites the
+	// writes and deletes made between the catch-up pass and the alias move are lost, and MaxCountDiff
+	// should tolerate them
+	AllowWrites bool
+
+	// MaxCountDiff the tolerated doc count difference between the old and the new index, 0 by default
+	MaxCountDiff  int64
+	DeleteOld     bool // delete the old index once the alias is moved, no Rollback possible afterwards
+	KeepOnFailure bool // keep the new index for inspection if failed, instead of rolling back
+
+	OnProgress func(phase string, status *BulkByScrollStatus)
+}
+
+// BlueGreenResult the outcome of BlueGreenReindex
+type BlueGreenResult struct {
+	Alias    string
+	OldIndex string
+	NewIndex string
+
+	Reindexed *BulkByScrollResponse // the summary of the first pass
+	CaughtUp  *BulkByScrollResponse // the summary of the catch-up pass
+	OldCount  int64
+	NewCount  int64
+
+	AliasMoved bool
+	OldDeleted bool
+	// OldWriteBlocked the old index keeps its write block after a success, so no write to it is lost,
+	// Rollback lifts it
+	OldWriteBlocked bool
+
+	es       *ElasticsearchEx
+	aliasDef *types.AliasDefinition
+}
+
+// ErrBlueGreenCountMismatch the doc count of the new index differs from the old one by more than MaxCountDiff
+type ErrBlueGreenCountMismatch struct {
+	OldIndex string
+	NewIndex string
+	OldCount int64
+	NewCount int64
+}
+
+func (e ErrBlueGreenCountMismatch) Error() string {
+	return fmt.Sprintf("doc count mismatch, %s=%d %s=%d", e.OldIndex, e.OldCount, e.NewIndex, e.NewCount)
+}
+
+// nextVersionedIndex orders_v6 -> orders_v7, or <alias>_v1 if the index is not versioned
+func nextVersionedIndex(alias, index string) string {
+	if m := versionedIndexRe.FindStringSubmatch(index); m != nil {
+		n, err := strconv.Atoi(m[2])
+		if err == nil {
+			return m[1] + "_v" + strconv.Itoa(n+1)
+		}
+	}
+	return alias + "_v1"
+}
+
+// BlueGreenReindex move the alias to a new index with the new mapping without downtime:
+//
+//  1. create the new index with refresh_interval -1 and no replicas
+//  2. reindex the old index into it with external versioning, which keeps the document versions
+//  3. block writes on the old index unless opts.AllowWrites, then catch up on the writes made during the first pass
+//  4. restore refresh_interval and number_of_replicas, from opts.Settings or else from the old index
+//  5. validate the doc counts, then atomically move the alias and optionally delete the old index
+//
+// Everything is rolled back on failure unless opts.KeepOnFailure, and BlueGreenResult.Rollback undoes a success
+// as long as the old index is kept. Note that documents deleted during the reindex are not caught up
+func (es *ElasticsearchEx) BlueGreenReindex(ctx context.Context, alias string, newMapping *types.TypeMapping, opts *BlueGreenOptions) (*BlueGreenResult, error) {
+	if opts == nil {
+		opts = &BlueGreenOptions{}
+	}
+	indices, err := es.aliasIndices(ctx, alias)
+	if err != nil {
+		return nil, err
+	}
+	if len(indices) != 1 {
+		return nil, fmt.Errorf("alias %s must point to exactly one index, got %v", alias, indices)
+	}
+	result := &BlueGreenResult{
+		Alias:    alias,
+		OldIndex: indices[0],
+		NewIndex: opts.NewIndex,
+		es:       es,
+	}
+	if result.NewIndex == "" {
+		result.NewIndex = nextVersionedIndex(alias, result.OldIndex)
+	}
+	// keep the filter, routing and is_write_index of the alias when moving it
+	aliases, err := es.GetAliases(ctx, result.OldIndex, alias)
+	if err != nil {
+		return nil, err
+	}
+	if def, ok := aliases[result.OldIndex].Aliases[alias]; ok {
+		result.aliasDef = &def
+	}
+
+	restore, err := es.blueGreenRestoreSettings(ctx, result.OldIndex, opts.Settings)
+	if err != nil {
+		return nil, err
+	}
+	settings := types.NewIndexSettings()
+	if opts.Settings != nil {
+		*settings = *opts.Settings
+	}
+	var refreshInterval types.Duration = "-1"
+	settings.RefreshInterval = &refreshInterval
+	settings.NumberOfReplicas = "0"
+	request := create.NewRequest()
+	request.Mappings = newMapping
+	request.Settings = settings
+	if _, err := es.IndexCreate(ctx, result.NewIndex, request); err != nil {
+		return nil, fmt.Errorf("create index %s failed: %w", result.NewIndex, err)
+	}
+
+	fail := func(err error) (*BlueGreenResult, error) {
+		if opts.KeepOnFailure {
+			return result, err
+		}
+		// ctx may be the reason of the failure, the rollback must run anyway to lift the write block
+		rollbackCtx, cancel := context.WithTimeout(context.Background(), blueGreenRollbackTimeout)
+		defer cancel()
+		if rollbackErr := result.Rollback(rollbackCtx); rollbackErr != nil {
+			return result, errors.Join(err, fmt.Errorf("rollback failed: %w", rollbackErr))
+		}
+		return result, err
+	}
+
+	spec := ReindexSpec{
+		Source:            []string{result.OldIndex},
+		Dest:              result.NewIndex,
+		VersionType:       &versiontype.External,
+		Pipeline:          opts.Pipeline,
+		Script:            opts.Script,
+		RequestsPerSecond: opts.RequestsPerSecond,
+		ConflictsProceed:  true,
+	}
+	if result.Reindexed, err = es.blueGreenPass(ctx, BlueGreenPhaseReindex, spec, opts); err != nil {
+		return fail(err)
+	}
+
+	if !opts.AllowWrites {
+		var rsp AcknowledgedResponse
+		if err := doGetResponse[ErrGeneric](ctx, es.Indices.AddBlock(result.OldIndex, "write"), &rsp); err != nil {
+			return fail(fmt.Errorf("block writes of %s failed: %w", result.OldIndex, err))
+		}
+		result.OldWriteBlocked = true
+	}
+	spec.Query = opts.CatchUpQuery
+	if result.CaughtUp, err = es.blueGreenPass(ctx, BlueGreenPhaseCatchUp, spec, opts); err != nil {
+		return fail(err)
+	}
+
+	if _, err := es.IndexPutSettingsRaw(ctx, result.NewIndex, restore); err != nil {
+		return fail(fmt.Errorf("restore settings of %s failed: %w", result.NewIndex, err))
+	}
+	if _, err := es.IndexRefresh(ctx, result.NewIndex); err != nil {
+		return fail(fmt.Errorf("refresh %s failed: %w", result.NewIndex, err))
+	}
+
+	if result.OldCount, err = es.CountAllByIndex(ctx, result.OldIndex); err != nil {
+		return fail(err)
+	}
+	if result.NewCount, err = es.CountAllByIndex(ctx, result.NewIndex); err != nil {
+		return fail(err)
+	}
+	diff := result.OldCount - result.NewCount
+	if diff < 0 {
+		diff = -diff
+	}
+	if diff > opts.MaxCountDiff {
+		return fail(ErrBlueGreenCountMismatch{
+			OldIndex: result.OldIndex,
+			NewIndex: result.NewIndex,
+			OldCount: result.OldCount,
+			NewCount: result.NewCount,
+		})
+	}
+
+	if err := es.SwapAlias(ctx, alias, result.OldIndex, result.NewIndex, result.aliasDef); err != nil {
+		return fail(fmt.Errorf("move alias %s failed: %w", alias, err))
+	}
+	result.AliasMoved = true
+
+	if opts.DeleteOld {
+		if _, err := es.IndexDelete(ctx, result.OldIndex); err != nil {
+			return result, fmt.Errorf("alias %s moved, but delete old index %s failed: %w", alias, result.OldIndex, err)
+		}
+		result.OldDeleted = true
+	}
+	return result, nil
+}
+
+// blueGreenRestoreSettings the settings applied to the new index once loaded, a nil value resets to the default
+func (es *ElasticsearchEx) blueGreenRestoreSettings(ctx context.Context, oldIndex string, desired *types.IndexSettings) (map[string]any, error) {
+	old, err := es.indexFlatSettings(ctx, oldIndex)
+	if err != nil {
+		return nil, err
+	}
+	restore := map[string]any{
+		"index.number_of_replicas": old["index.number_of_replicas"],
+		"index.refresh_interval":   old["index.refresh_interval"],
+	}
+	if desired != nil && desired.NumberOfReplicas != "" {
+		restore["index.number_of_replicas"] = desired.NumberOfReplicas
+	}
+	if desired != nil && desired.RefreshInterval != nil {
+		restore["index.refresh_interval"] = *desired.RefreshInterval
+	}
+	return restore, nil
+}
+
+func (es *ElasticsearchEx) blueGreenPass(ctx context.Context, phase string, spec ReindexSpec, opts *BlueGreenOptions) (*BulkByScrollResponse, error) {
+	task, err := es.Reindex(ctx, spec)
+	if err != nil {
+		return nil, fmt.Errorf("%s failed: %w", phase, err)
+	}
+	var onProgress func(status *BulkByScrollStatus)
+	if opts.OnProgress != nil {
+		onProgress = func(status *BulkByScrollStatus) {
+			opts.OnProgress(phase, status)
+		}
+	}
+	rsp, err := task.WaitProgress(ctx, onProgress)
+	if err != nil {
+		return rsp, fmt.Errorf("%s failed: %w", phase, err)
+	}
+	return rsp, nil
+}
+
+// Rollback move the alias back to the old index, lift the write block and delete the new index,
+// it is not possible once the old index is deleted
+func (r *BlueGreenResult) Rollback(ctx context.Context) error {
+	if r.OldDeleted {
+		return fmt.Errorf("old index %s is deleted, cannot rollback", r.OldIndex)
+	}
+	if r.OldWriteBlocked {
+		if _, err := r.es.IndexPutSettingsRaw(ctx, r.OldIndex, map[string]any{"index.blocks.write": false}); err != nil {
+			return fmt.Errorf("lift the write block of %s failed: %w", r.OldIndex, err)
+		}
+		r.OldWriteBlocked = false
+	}
+	if r.AliasMoved {
+		if err := r.es.SwapAlias(ctx, r.Alias, r.NewIndex, r.OldIndex, r.aliasDef); err != nil {
+			return fmt.Errorf("move alias %s back failed: %w", r.Alias, err)
+		}
+		r.AliasMoved = false
+	}
+	if _, err := r.es.IndexDelete(ctx, r.NewIndex); err != nil {
+		return fmt.Errorf("delete new index %s failed: %w", r.NewIndex, err)
+	}
+	return nil
+}
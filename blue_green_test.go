@@ -0,0 +1,111 @@
+package elastic_wrapper
+
+import (
+	"context"
+	"strconv"
+	"testing"
+)
+
+func TestNextVersionedIndex(t *testing.T) {
+	cases := map[string]string{
+		"orders_v6":    "orders_v7",
+		"orders_v9":    "orders_v10",
+		"my_orders_v1": "my_orders_v2",
+		"orders-2023":  "orders_v1",
+		"orders_vx":    "orders_v1",
+	}
+	for index, expect := range cases {
+		if got := nextVersionedIndex("orders", index); got != expect {
+			t.Errorf("nextVersionedIndex(%s) expect %s, got %s", index, expect, got)
+		}
+	}
+}
+
+func TestBlueGreenReindex(t *testing.T) {
+	es := newClient(t)
+	ctx := context.Background()
+
+	alias := "test_blue_green"
+	oldIndex := alias + "_v1"
+
+	type order struct {
+		ID    string `json:"id"`
+		Title string `json:"title" es:"text"`
+	}
+	type orderV2 struct {
+		ID    string `json:"id"`
+		Title string `json:"title" es:"text,analyzer=english,keyword"`
+	}
+
+	t.Cleanup(func() {
+		for _, index := range []string{oldIndex, alias + "_v2"} {
+			if _, err := es.IndexDelete(context.Background(), index); err != nil {
+				t.Fatalf("delete index %s failed, err=%v", index, err)
+			}
+		}
+	})
+
+	if _, err := IndexCreateFor[order](ctx, es, oldIndex); err != nil {
+		t.Fatalf("create index failed, err=%v", err)
+	}
+	if err := es.AddAlias(ctx, oldIndex, alias, nil); err != nil {
+		t.Fatalf("add alias failed, err=%v", err)
+	}
+	n := 20
+	for i := 1; i <= n; i++ {
+		id := strconv.Itoa(i)
+		if _, err := es.DocIndexSimple(ctx, alias, id, order{ID: id, Title: "running orders " + id}); err != nil {
+			t.Fatalf("index doc failed, err=%v", err)
+		}
+	}
+	// an explicit setting of the old index, which should be restored on the new index
+	if _, err := es.IndexPutSettingsRaw(ctx, oldIndex, map[string]any{"index.refresh_interval": "1s"}); err != nil {
+		t.Fatalf("put settings failed, err=%v", err)
+	}
+
+	mapping, err := MappingFor[orderV2]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := es.BlueGreenReindex(ctx, alias, mapping, &BlueGreenOptions{
+		OnProgress: func(phase string, status *BulkByScrollStatus) {
+			t.Logf("phase=%s done=%d/%d", phase, status.Done(), status.Total)
+		},
+	})
+	if err != nil {
+		t.Fatalf("blue green reindex failed, err=%v", err)
+	}
+	t.Logf("result=%+v", result)
+	if result.NewIndex != alias+"_v2" || result.OldCount != int64(n) || result.NewCount != int64(n) || !result.OldWriteBlocked {
+		t.Fatalf("unexpected result=%+v", result)
+	}
+
+	indices, err := es.aliasIndices(ctx, alias)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(indices) != 1 || indices[0] != result.NewIndex {
+		t.Fatalf("alias should point to the new index, indices=%v", indices)
+	}
+	settings, err := es.indexFlatSettings(ctx, result.NewIndex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settings["index.refresh_interval"] != "1s" {
+		t.Errorf("refresh_interval should be restored, settings=%v", settings)
+	}
+
+	if err := result.Rollback(ctx); err != nil {
+		t.Fatalf("rollback failed, err=%v", err)
+	}
+	indices, err = es.aliasIndices(ctx, alias)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(indices) != 1 || indices[0] != oldIndex {
+		t.Fatalf("alias should point back to the old index, indices=%v", indices)
+	}
+	if _, err := es.DocIndexSimple(ctx, alias, "new", order{ID: "new"}); err != nil {
+		t.Fatalf("old index should be writable after rollback, err=%v", err)
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"net/http"
 
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/versiontype"
 	"github.com/olivere/ndjson"
 
 	"github.com/ttys3/elastic-wrapper-go/bulk_index"
@@ -14,6 +16,18 @@ type Document interface {
 	GetID() string
 }
 
+// Versioned documents carry their own external version, e.g. the row version of a database,
+// bulk helpers pick it up when a version type is given by WithVersionType
+type Versioned interface {
+	GetVersion() int64
+}
+
+// Routed documents carry their own custom routing, e.g. the tenant id,
+// bulk helpers pick it up the same way as GetID, it takes precedence over WithRouting
+type Routed interface {
+	GetRouting() string
+}
+
 // {"took":38,"errors":false,"items":[
 // {"index":{"_index":"test-bulk-example","_type":"_doc","_id":"1","_version":1,"result":"created","_shards":{"total":2,"successful":1,"failed":0},"_seq_no":0,"_primary_term":1,"status":201}},
 // {"index":{"_index":"test-bulk-example","_type":"_doc","_id":"2","_version":1,"result":"created","_shards":{"total":2,"successful":1,"failed":0},"_seq_no":1,"_primary_term":1,"status":201}},
@@ -65,6 +79,21 @@ func (e BulkIndexError) Error() string {
 	return fmt.Sprintf("bulk index error: %s, code: %v", e.TheError.Reason, e.Status)
 }
 
+// BulkItemsError the bulk request succeeded, but some items failed
+type BulkItemsError struct {
+	Items []BulkResponseItem
+}
+
+func (e BulkItemsError) Error() string {
+	first := e.Items[0]
+	reason := ""
+	if first.Detail.Error != nil {
+		reason = first.Detail.Error.Type + ": " + first.Detail.Error.Reason
+	}
+	return fmt.Sprintf("bulk has %d failed items, first: action=%s id=%s status=%d error=%s",
+		len(e.Items), first.Type, first.Detail.ID, first.Detail.Status, reason)
+}
+
 type BulkIndexerStats struct {
 	NumFailed   uint64
 	NumIndexed  uint64
@@ -72,21 +101,94 @@ type BulkIndexerStats struct {
 }
 
 type Meta struct {
-	Index string `json:"_index,omitempty"`
-	ID    string `json:"_id"`
+	Index       string `json:"_index,omitempty"`
+	ID          string `json:"_id"`
+	Routing     string `json:"routing,omitempty"`
+	Version     *int64 `json:"version,omitempty"`
+	VersionType string `json:"version_type,omitempty"`
+	Pipeline    string `json:"pipeline,omitempty"`
 }
 
 type ActionAndMeta map[string]Meta
 
-func (es *ElasticsearchEx) BulkIndexOrCreate(ctx context.Context, action, indexName string, items []Document) error {
+// bulkMeta the action_and_meta_data of the item, version is only set when a version type is given
+func bulkMeta(id string, version *int64, o *requestOptions) Meta {
+	meta := Meta{ID: id, Routing: o.routing}
+	if o.versionType != nil && version != nil {
+		meta.Version = version
+		meta.VersionType = o.versionType.String()
+	}
+	return meta
+}
+
+// doBulkResponse send the bulk body, the items of the response are in the order of the request
+func (es *ElasticsearchEx) doBulkResponse(ctx context.Context, indexName string, body []byte) (*BulkOperateResponse, error) {
+	builkIndex := bulk_index.NewBulkIndexFunc(es.TypedClient)
+
+	req := builkIndex(indexName).Raw(body)
+	var rsp BulkOperateResponse
+	if err := doGetResponse[BulkIndexError](ctx, req, &rsp); err != nil {
+		return nil, err
+	}
+	return &rsp, nil
+}
+
+// doBulk send the bulk body, the failures of single items are not reported, except the stale items of
+// a versioned bulk which are returned as ErrStaleItems. Only a conflict of index or delete is stale,
+// a conflict of create means the document already exists
+func (es *ElasticsearchEx) doBulk(ctx context.Context, indexName string, body []byte, o *requestOptions) error {
+	rsp, err := es.doBulkResponse(ctx, indexName, body)
+	if err != nil {
+		return err
+	}
+	if o.versionType == nil {
+		return nil
+	}
+
+	var stale []string
+	for _, item := range rsp.ErrorItems() {
+		if item.Detail.Status == http.StatusConflict && (item.Type == "index" || item.Type == "delete") {
+			stale = append(stale, item.Detail.ID)
+		}
+	}
+	if len(stale) > 0 {
+		return ErrStaleItems{IDs: stale}
+	}
+	return nil
+}
+
+// BulkIndexOrCreate index or create the documents in bulk
+// supported options: WithPipeline, WithRouting, WithVersionType, WithExternalVersion, WithExternalGteVersion,
+// the version and routing of each document are read from Versioned and Routed, or the ones from the options.
+// Stale items of a versioned bulk are skipped and returned as ErrStaleItems, es does not support versioning
+// on create, so the version options of create return ErrCreateVersioning, and WithVersion without a version type
+// returns ErrVersionWithoutType
+func (es *ElasticsearchEx) BulkIndexOrCreate(ctx context.Context, action, indexName string, items []Document, opts ...RequestOption) error {
 	if action != "index" && action != "create" {
 		return fmt.Errorf("action must be index or create")
 	}
+	o := newRequestOptions(opts)
+	if action == "create" && (o.version != nil || o.versionType != nil) {
+		return ErrCreateVersioning
+	}
+	if err := o.checkWriteVersion(); err != nil {
+		return err
+	}
 	buf := bytes.NewBuffer(nil)
 	w := ndjson.NewWriter(buf)
 	for _, a := range items {
+		version := o.version
+		if v, ok := a.(Versioned); ok {
+			docVersion := v.GetVersion()
+			version = &docVersion
+		}
+		meta := bulkMeta(a.GetID(), version, o)
+		meta.Pipeline = o.pipeline
+		if r, ok := a.(Routed); ok && r.GetRouting() != "" {
+			meta.Routing = r.GetRouting()
+		}
 		// action_and_meta_data\n
-		err := w.Encode(ActionAndMeta{action: Meta{ID: a.GetID()}})
+		err := w.Encode(ActionAndMeta{action: meta})
 		if err != nil {
 			return fmt.Errorf("cannot encode action_and_meta_data %s: %s", a.GetID(), err)
 		}
@@ -95,33 +197,30 @@ func (es *ElasticsearchEx) BulkIndexOrCreate(ctx context.Context, action, indexN
 		if err != nil {
 			return fmt.Errorf("cannot encode document %s: %s", a.GetID(), err)
 		}
-		if err != nil {
-			return err
-		}
 	}
 
-	builkIndex := bulk_index.NewBulkIndexFunc(es.TypedClient)
-
-	req := builkIndex(indexName).Raw(buf.Bytes())
-	var rsp BuilkIndexResponse
-	return doGetResponse[BulkIndexError](ctx, req, &rsp)
+	return es.doBulk(ctx, indexName, buf.Bytes(), o)
 }
 
-func (es *ElasticsearchEx) BulkIndex(ctx context.Context, indexName string, items []Document) error {
-	return es.BulkIndexOrCreate(ctx, "index", indexName, items)
+// BulkIndex data streams reject the index op, use BulkCreate or DataStreamWriter for them
+func (es *ElasticsearchEx) BulkIndex(ctx context.Context, indexName string, items []Document, opts ...RequestOption) error {
+	return es.BulkIndexOrCreate(ctx, "index", indexName, items, opts...)
 }
 
-func (es *ElasticsearchEx) BulkCreate(ctx context.Context, indexName string, items []Document) error {
-	return es.BulkIndexOrCreate(ctx, "create", indexName, items)
+func (es *ElasticsearchEx) BulkCreate(ctx context.Context, indexName string, items []Document, opts ...RequestOption) error {
+	return es.BulkIndexOrCreate(ctx, "create", indexName, items, opts...)
 }
 
-func (es *ElasticsearchEx) BulkUpdate(ctx context.Context, indexName string, updates map[string]map[string]any) error {
+// BulkUpdate partial update documents in bulk, supported options: WithRouting,
+// es does not support external versioning nor ingest pipelines for update
+func (es *ElasticsearchEx) BulkUpdate(ctx context.Context, indexName string, updates map[string]map[string]any, opts ...RequestOption) error {
 	action := "update"
+	o := newRequestOptions(opts)
 	buf := bytes.NewBuffer(nil)
 	w := ndjson.NewWriter(buf)
 	for id, doc := range updates {
 		// action_and_meta_data\n
-		err := w.Encode(ActionAndMeta{action: Meta{ID: id}})
+		err := w.Encode(ActionAndMeta{action: Meta{ID: id, Routing: o.routing}})
 		if err != nil {
 			return fmt.Errorf("cannot encode action_and_meta_data %s: %s", id, err)
 		}
@@ -132,28 +231,46 @@ func (es *ElasticsearchEx) BulkUpdate(ctx context.Context, indexName string, upd
 		}
 	}
 
-	builkIndex := bulk_index.NewBulkIndexFunc(es.TypedClient)
+	return es.doBulk(ctx, indexName, buf.Bytes(), o)
+}
+
+// BulkDelete delete documents in bulk, supported options: WithRouting, WithVersionType, WithExternalVersion, WithExternalGteVersion
+func (es *ElasticsearchEx) BulkDelete(ctx context.Context, indexName string, ids []string, opts ...RequestOption) error {
+	return es.bulkDelete(ctx, indexName, ids, nil, newRequestOptions(opts))
+}
 
-	req := builkIndex(indexName).Raw(buf.Bytes())
-	var rsp BuilkIndexResponse
-	return doGetResponse[BulkIndexError](ctx, req, &rsp)
+// BulkDeleteVersioned delete documents with their external version (id -> version) in bulk,
+// the version type is external unless WithVersionType is given, stale deletes are skipped and returned as ErrStaleItems
+func (es *ElasticsearchEx) BulkDeleteVersioned(ctx context.Context, indexName string, versions map[string]int64, opts ...RequestOption) error {
+	o := newRequestOptions(append([]RequestOption{WithVersionType(versiontype.External)}, opts...))
+	ids := make([]string, 0, len(versions))
+	versionPtrs := make(map[string]*int64, len(versions))
+	for id, version := range versions {
+		version := version
+		ids = append(ids, id)
+		versionPtrs[id] = &version
+	}
+	return es.bulkDelete(ctx, indexName, ids, versionPtrs, o)
 }
 
-func (es *ElasticsearchEx) BulkDelete(ctx context.Context, indexName string, ids []string) error {
+func (es *ElasticsearchEx) bulkDelete(ctx context.Context, indexName string, ids []string, versions map[string]*int64, o *requestOptions) error {
+	if err := o.checkWriteVersion(); err != nil {
+		return err
+	}
 	action := "delete"
 	buf := bytes.NewBuffer(nil)
 	w := ndjson.NewWriter(buf)
 	for _, id := range ids {
+		version, ok := versions[id]
+		if !ok {
+			version = o.version
+		}
 		// action_and_meta_data\n
-		err := w.Encode(ActionAndMeta{action: Meta{ID: id}})
+		err := w.Encode(ActionAndMeta{action: bulkMeta(id, version, o)})
 		if err != nil {
 			return fmt.Errorf("cannot encode action_and_meta_data %s: %s", id, err)
 		}
 	}
 
-	builkIndex := bulk_index.NewBulkIndexFunc(es.TypedClient)
-
-	req := builkIndex(indexName).Raw(buf.Bytes())
-	var rsp BuilkIndexResponse
-	return doGetResponse[BulkIndexError](ctx, req, &rsp)
+	return es.doBulk(ctx, indexName, buf.Bytes(), o)
 }
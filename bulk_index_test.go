@@ -2,6 +2,7 @@ package elastic_wrapper
 
 import (
 	"context"
+	"errors"
 	"log"
 	"math/rand"
 	"strconv"
@@ -10,6 +11,10 @@ import (
 	"time"
 
 	"github.com/dustin/go-humanize"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/versiontype"
+
+	"github.com/ttys3/elastic-wrapper-go/scripts"
 )
 
 type article struct {
@@ -154,3 +159,144 @@ func TestElasticsearchEx_BulkUpdate(t *testing.T) {
 		}
 	}
 }
+
+type versionedArticle struct {
+	article
+	Version int64 `json:"-"`
+}
+
+func (a *versionedArticle) GetVersion() int64 {
+	return a.Version
+}
+
+func TestElasticsearchEx_BulkIndexExternalVersion(t *testing.T) {
+	indexName := "test-bulk-external-version"
+
+	es := newClient(t)
+	ctx := context.Background()
+
+	t.Cleanup(func() {
+		if _, err := es.IndexDelete(context.Background(), indexName); err != nil {
+			t.Fatalf("→ Failed to delete index %s: %v", indexName, err)
+		}
+	})
+
+	items := []Document{
+		&versionedArticle{article: article{ID: 1, Title: "v5"}, Version: 5},
+		&versionedArticle{article: article{ID: 2, Title: "v5"}, Version: 5},
+	}
+	err := es.BulkIndex(ctx, indexName, items, WithVersionType(versiontype.External))
+	if err != nil {
+		t.Fatalf("→ Failed to bulk index: %v", err)
+	}
+
+	// the first one is stale and skipped
+	items = []Document{
+		&versionedArticle{article: article{ID: 1, Title: "v4"}, Version: 4},
+		&versionedArticle{article: article{ID: 2, Title: "v6"}, Version: 6},
+	}
+	err = es.BulkIndex(ctx, indexName, items, WithVersionType(versiontype.External))
+	var errStale ErrStaleItems
+	if !errors.Is(err, ErrStaleVersion) || !errors.As(err, &errStale) || len(errStale.IDs) != 1 || errStale.IDs[0] != "1" {
+		t.Fatalf("→ expect the first item stale, err=%v", err)
+	}
+
+	err = es.BulkCreate(ctx, indexName, items, WithVersionType(versiontype.External))
+	if !errors.Is(err, ErrCreateVersioning) {
+		t.Fatalf("→ expect versioned create rejected, err=%v", err)
+	}
+	err = es.BulkDelete(ctx, indexName, []string{"1"}, WithVersion(5))
+	if !errors.Is(err, ErrVersionWithoutType) {
+		t.Fatalf("→ expect version without a version type rejected, err=%v", err)
+	}
+
+	var result DocsResponse[article]
+	err = es.GetDocumentByIDs(ctx, indexName, []string{"1", "2"}, &result)
+	if err != nil {
+		t.Fatalf("→ Failed to mget: %v", err)
+	}
+	if result.Docs[0].Source.Title != "v5" || result.Docs[0].Version != 5 {
+		t.Errorf("→ stale write should be skipped, doc=%+v", result.Docs[0])
+	}
+	if result.Docs[1].Source.Title != "v6" || result.Docs[1].Version != 6 {
+		t.Errorf("→ newer write should win, doc=%+v", result.Docs[1])
+	}
+
+	err = es.BulkDeleteVersioned(ctx, indexName, map[string]int64{"1": 5, "2": 7})
+	if err != nil {
+		t.Fatalf("→ Failed to bulk delete: %v", err)
+	}
+}
+
+type tenantArticle struct {
+	article
+	Tenant string `json:"tenant"`
+}
+
+func (a *tenantArticle) GetRouting() string {
+	return a.Tenant
+}
+
+func TestElasticsearchEx_BulkIndexRouted(t *testing.T) {
+	indexName := "test-bulk-routed"
+
+	es := newClient(t)
+	ctx := context.Background()
+	if err := es.RegisterCounterScript(ctx); err != nil {
+		t.Fatalf("→ Failed to register counter script: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if _, err := es.IndexDelete(context.Background(), indexName); err != nil {
+			t.Fatalf("→ Failed to delete index %s: %v", indexName, err)
+		}
+	})
+
+	// routing is required, every operation without it fails
+	_, err := es.IndexCreateSimple(ctx, indexName, &types.TypeMapping{Routing_: &types.RoutingField{Required: true}})
+	if err != nil {
+		t.Fatalf("→ Failed to create index: %v", err)
+	}
+
+	items := []Document{
+		&tenantArticle{article: article{ID: 1, Title: "one"}, Tenant: "tenant-a"},
+		&tenantArticle{article: article{ID: 2, Title: "two"}, Tenant: "tenant-b"},
+	}
+	err = es.BulkIndex(ctx, indexName, items)
+	if err != nil {
+		t.Fatalf("→ Failed to bulk index: %v", err)
+	}
+
+	doc, err := DocGetSource[tenantArticle](ctx, es, indexName, "1", WithRouting("tenant-a"))
+	if err != nil {
+		t.Fatalf("→ Failed to get routed doc: %v", err)
+	}
+	if doc.Title != "one" {
+		t.Errorf("→ unexpected doc=%+v", doc)
+	}
+	if _, err := DocGetSource[tenantArticle](ctx, es, indexName, "1"); err == nil {
+		t.Errorf("→ get without routing should fail")
+	}
+
+	_, err = es.DocUpdateSimple(ctx, indexName, "2", map[string]any{"title": "two updated"}, WithRouting("tenant-b"))
+	if err != nil {
+		t.Fatalf("→ Failed to update routed doc: %v", err)
+	}
+	_, err = es.DocUpdateCounterSimpleRouted(ctx, indexName, "2", "tenant-b", 1, "views")
+	if err != nil {
+		t.Fatalf("→ Failed to update routed counter: %v", err)
+	}
+	_, err = es.DocUpdateCounterFieldsRouted(ctx, indexName, "2", "tenant-b", scripts.IncrBy("views", 1))
+	if err != nil {
+		t.Fatalf("→ Failed to update routed counter fields: %v", err)
+	}
+
+	err = es.BulkDelete(ctx, indexName, []string{"1"}, WithRouting("tenant-a"))
+	if err != nil {
+		t.Fatalf("→ Failed to bulk delete: %v", err)
+	}
+	_, err = es.DocDelete(ctx, indexName, "2", WithRouting("tenant-b"))
+	if err != nil {
+		t.Fatalf("→ Failed to delete routed doc: %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+package elastic_wrapper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// the mapping _meta key of the bulk load record, the settings to restore once the bulk load is over
+const bulkLoadMetaKey = "elastic_wrapper_bulk_load"
+
+// the settings applied during a bulk load, no refresh and no replicas to copy the writes to
+var bulkLoadSettings = map[string]any{
+	"index.refresh_interval":   "-1",
+	"index.number_of_replicas": "0",
+}
+
+// WithBulkLoadMode run fn with the index tuned for heavy ingestion: refresh_interval -1 and no replicas.
+// The original settings are always restored afterwards, even if fn fails or panics, followed by a refresh.
+//
+// Before changing anything the original settings are recorded in the `_meta` of the index mapping,
+// so if the process crashes during the load, RestoreBulkLoadMode restores them later, e.g. at startup.
+// If a record already exists, another load is running or a crashed one left it over, so ErrBulkLoadActive
+// is returned instead of restoring the settings under the running load, see RestoreBulkLoadMode
+func (es *ElasticsearchEx) WithBulkLoadMode(ctx context.Context, index string, fn func(ctx context.Context) error) (err error) {
+	record, err := es.bulkLoadRecord(ctx, index)
+	if err != nil {
+		return err
+	}
+	if record != nil {
+		return fmt.Errorf("%s: %w", index, ErrBulkLoadActive)
+	}
+	current, err := es.indexFlatSettings(ctx, index)
+	if err != nil {
+		return err
+	}
+	record = make(map[string]any, len(bulkLoadSettings))
+	for key := range bulkLoadSettings {
+		// an empty string records a setting which is not explicitly set, _meta values cannot be null
+		record[key] = ""
+		if v, ok := current[key]; ok && v != nil {
+			record[key] = v
+		}
+	}
+	if err := es.putBulkLoadRecord(ctx, index, record); err != nil {
+		return fmt.Errorf("record the settings of %s failed: %w", index, err)
+	}
+
+	// the restore uses its own context, so it still runs when ctx is canceled
+	defer func() {
+		if r := recover(); r != nil {
+			_, _ = es.RestoreBulkLoadMode(context.Background(), index)
+			panic(r)
+		}
+		if _, restoreErr := es.RestoreBulkLoadMode(context.Background(), index); restoreErr != nil {
+			err = errors.Join(err, fmt.Errorf("restore the settings of %s failed: %w", index, restoreErr))
+		}
+	}()
+
+	if _, err := es.IndexPutSettingsRaw(ctx, index, bulkLoadSettings); err != nil {
+		return fmt.Errorf("apply the bulk load settings to %s failed: %w", index, err)
+	}
+	return fn(ctx)
+}
+
+// RestoreBulkLoadMode restore the settings recorded by WithBulkLoadMode and refresh the index,
+// false if there is nothing to restore. It is idempotent, and meant to be called at startup,
+// before any load runs, to recover from a crashed load.
+//
+// The record lives in the mapping `_meta`, ApplyMapping keeps it when it replaces the `_meta`,
+// a put mapping of a custom `_meta` outside this package drops it
+func (es *ElasticsearchEx) RestoreBulkLoadMode(ctx context.Context, index string) (bool, error) {
+	record, err := es.bulkLoadRecord(ctx, index)
+	if err != nil || record == nil {
+		return false, err
+	}
+	settings := make(map[string]any, len(record))
+	for key, v := range record {
+		if v == "" {
+			v = nil
+		}
+		settings[key] = v
+	}
+	if _, err := es.IndexPutSettingsRaw(ctx, index, settings); err != nil {
+		return false, err
+	}
+	if _, err := es.IndexRefresh(ctx, index); err != nil {
+		return false, fmt.Errorf("refresh %s failed: %w", index, err)
+	}
+	// the record is only dropped once restored, a crash before this point restores again next time
+	if err := es.putBulkLoadRecord(ctx, index, nil); err != nil {
+		return false, fmt.Errorf("drop the bulk load record of %s failed: %w", index, err)
+	}
+	return true, nil
+}
+
+// bulkLoadRecord the recorded settings, nil if the index is not in bulk load mode
+func (es *ElasticsearchEx) bulkLoadRecord(ctx context.Context, index string) (map[string]any, error) {
+	mapping, err := es.liveMapping(ctx, index)
+	if err != nil {
+		return nil, err
+	}
+	meta, _ := mapping["_meta"].(map[string]any)
+	record, _ := meta[bulkLoadMetaKey].(map[string]any)
+	return record, nil
+}
+
+// putBulkLoadRecord set the record in the mapping _meta, or drop it if nil, the other _meta keys are kept
+func (es *ElasticsearchEx) putBulkLoadRecord(ctx context.Context, index string, record map[string]any) error {
+	mapping, err := es.liveMapping(ctx, index)
+	if err != nil {
+		return err
+	}
+	// _meta is replaced as a whole by put mapping
+	meta := map[string]any{}
+	if live, ok := mapping["_meta"].(map[string]any); ok {
+		for k, v := range live {
+			meta[k] = v
+		}
+	}
+	if record == nil {
+		delete(meta, bulkLoadMetaKey)
+	} else {
+		meta[bulkLoadMetaKey] = record
+	}
+	body, err := json.Marshal(map[string]any{"_meta": meta})
+	if err != nil {
+		return err
+	}
+	var rsp AcknowledgedResponse
+	return doGetResponse[ErrGeneric](ctx, es.Indices.PutMapping(index).Raw(body), &rsp)
+}
@@ -0,0 +1,130 @@
+package elastic_wrapper
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/indices/create"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+)
+
+func TestWithBulkLoadMode(t *testing.T) {
+	es := newClient(t)
+	ctx := context.Background()
+
+	demoIndex := "test_bulk_load_mode"
+
+	t.Cleanup(func() {
+		deleted, err := es.IndexDelete(context.Background(), demoIndex)
+		if err != nil {
+			t.Fatalf("delete index failed, err=%v", err)
+		}
+		if !deleted {
+			t.Fatalf("delete index failed, deleted=%v", deleted)
+		}
+	})
+
+	req := create.NewRequest()
+	var refreshInterval types.Duration = "5s"
+	req.Settings = &types.IndexSettings{RefreshInterval: &refreshInterval}
+	if _, err := es.IndexCreate(ctx, demoIndex, req); err != nil {
+		t.Fatalf("create index failed, err=%v", err)
+	}
+
+	assertSettings := func(refreshInterval, replicas any) {
+		t.Helper()
+		settings, err := es.indexFlatSettings(ctx, demoIndex)
+		if err != nil {
+			t.Fatalf("get settings failed, err=%v", err)
+		}
+		if settings["index.refresh_interval"] != refreshInterval || settings["index.number_of_replicas"] != replicas {
+			t.Fatalf("expect refresh_interval=%v number_of_replicas=%v, settings=%v", refreshInterval, replicas, settings)
+		}
+	}
+
+	errLoad := errors.New("load failed")
+	err := es.WithBulkLoadMode(ctx, demoIndex, func(ctx context.Context) error {
+		assertSettings("-1", "0")
+		return errLoad
+	})
+	if !errors.Is(err, errLoad) {
+		t.Fatalf("expect the error of the load, err=%v", err)
+	}
+	assertSettings("5s", "1")
+
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Fatalf("expect the panic to be propagated")
+			}
+		}()
+		_ = es.WithBulkLoadMode(ctx, demoIndex, func(ctx context.Context) error {
+			panic("load panicked")
+		})
+	}()
+	assertSettings("5s", "1")
+
+	// a crashed load leaves the record and the load settings behind
+	if err := es.putBulkLoadRecord(ctx, demoIndex, map[string]any{
+		"index.refresh_interval":   "5s",
+		"index.number_of_replicas": "1",
+	}); err != nil {
+		t.Fatalf("put record failed, err=%v", err)
+	}
+	if _, err := es.IndexPutSettingsRaw(ctx, demoIndex, bulkLoadSettings); err != nil {
+		t.Fatalf("put settings failed, err=%v", err)
+	}
+	err = es.WithBulkLoadMode(ctx, demoIndex, func(ctx context.Context) error {
+		t.Fatalf("the load should not run while a record exists")
+		return nil
+	})
+	if !errors.Is(err, ErrBulkLoadActive) {
+		t.Fatalf("expect ErrBulkLoadActive, err=%v", err)
+	}
+	restored, err := es.RestoreBulkLoadMode(ctx, demoIndex)
+	if err != nil || !restored {
+		t.Fatalf("restore failed, restored=%v err=%v", restored, err)
+	}
+	assertSettings("5s", "1")
+	restored, err = es.RestoreBulkLoadMode(ctx, demoIndex)
+	if err != nil || restored {
+		t.Fatalf("expect nothing to restore, restored=%v err=%v", restored, err)
+	}
+}
+
+func TestIndexPutSettings(t *testing.T) {
+	es := newClient(t)
+	ctx := context.Background()
+
+	demoIndex := "test_index_put_settings"
+
+	t.Cleanup(func() {
+		deleted, err := es.IndexDelete(context.Background(), demoIndex)
+		if err != nil {
+			t.Fatalf("delete index failed, err=%v", err)
+		}
+		if !deleted {
+			t.Fatalf("delete index failed, deleted=%v", deleted)
+		}
+	})
+
+	if _, err := es.IndexCreateSimple(ctx, demoIndex, nil); err != nil {
+		t.Fatalf("create simple index failed, err=%v", err)
+	}
+	var refreshInterval types.Duration = "30s"
+	rsp, err := es.IndexPutSettings(ctx, demoIndex, &types.IndexSettings{RefreshInterval: &refreshInterval, NumberOfReplicas: "0"})
+	if err != nil {
+		t.Fatalf("put settings failed, err=%v", err)
+	}
+	if !rsp.Acknowledged {
+		t.Fatalf("expect acknowledged, rsp=%+v", rsp)
+	}
+	settings, err := es.indexFlatSettings(ctx, demoIndex)
+	if err != nil {
+		t.Fatalf("get settings failed, err=%v", err)
+	}
+	if settings["index.refresh_interval"] != "30s" || settings["index.number_of_replicas"] != "0" {
+		t.Fatalf("expect the typed settings applied, settings=%v", settings)
+	}
+}
@@ -0,0 +1,89 @@
+package elastic_wrapper
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/core/deletebyquery"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/core/updatebyquery"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/conflicts"
+)
+
+const defaultSlices = "auto"
+
+// byQueryRequest is the builder set shared by the delete by query and update by query API
+type byQueryRequest[R any] interface {
+	Slices(value string) R
+	RequestsPerSecond(value string) R
+	Conflicts(enum conflicts.Conflicts) R
+	MaxDocs(value string) R
+	Routing(value string) R
+	WaitForCompletion(b bool) R
+}
+
+var (
+	_ byQueryRequest[*deletebyquery.DeleteByQuery] = (*deletebyquery.DeleteByQuery)(nil)
+	_ byQueryRequest[*updatebyquery.UpdateByQuery] = (*updatebyquery.UpdateByQuery)(nil)
+)
+
+func applyByQueryOptions[R byQueryRequest[R]](req R, o *requestOptions) R {
+	slices := o.slices
+	if slices == "" {
+		slices = defaultSlices
+	}
+	req.Slices(slices)
+	if o.requestsPerSecond != nil {
+		req.RequestsPerSecond(formatRequestsPerSecond(*o.requestsPerSecond))
+	}
+	if o.conflictsProceed {
+		req.Conflicts(conflicts.Proceed)
+	}
+	if o.maxDocs != nil {
+		req.MaxDocs(strconv.FormatInt(*o.maxDocs, 10))
+	}
+	if o.routing != "" {
+		req.Routing(o.routing)
+	}
+	// always run as a background task, the caller tracks it by the returned Task
+	req.WaitForCompletion(false)
+	return req
+}
+
+// DeleteByQuery deletes documents that match the query as a background task
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/docs-delete-by-query.html
+// slices default to auto, supported options: WithSlices, WithRequestsPerSecond, WithConflictsProceed, WithMaxDocs, WithRouting
+func (es *ElasticsearchEx) DeleteByQuery(ctx context.Context, index string, query *types.Query, opts ...RequestOption) (*Task, error) {
+	request := deletebyquery.NewRequest()
+	request.Query = query
+	req := applyByQueryOptions(es.Core.DeleteByQuery(index).Request(request), newRequestOptions(opts))
+
+	var rsp taskStartResponse
+	if err := doGetResponse[ErrGeneric](ctx, req, &rsp); err != nil {
+		return nil, err
+	}
+	return newTask(es, rsp.Task, func(ctx context.Context, taskID, rps string) error {
+		var rsp ResponseGeneric
+		return doGetResponse[ErrGeneric](ctx, es.Core.DeleteByQueryRethrottle(taskID).RequestsPerSecond(rps), &rsp)
+	}), nil
+}
+
+// UpdateByQuery updates documents that match the query with the script as a background task,
+// a nil script just reindex the documents in place to pick up mapping changes
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/docs-update-by-query.html
+// slices default to auto, supported options: WithSlices, WithRequestsPerSecond, WithConflictsProceed, WithMaxDocs, WithRouting
+func (es *ElasticsearchEx) UpdateByQuery(ctx context.Context, index string, query *types.Query, script *types.Script, opts ...RequestOption) (*Task, error) {
+	request := updatebyquery.NewRequest()
+	request.Query = query
+	request.Script = script
+	req := applyByQueryOptions(es.Core.UpdateByQuery(index).Request(request), newRequestOptions(opts))
+
+	var rsp taskStartResponse
+	if err := doGetResponse[ErrGeneric](ctx, req, &rsp); err != nil {
+		return nil, err
+	}
+	return newTask(es, rsp.Task, func(ctx context.Context, taskID, rps string) error {
+		var rsp ResponseGeneric
+		return doGetResponse[ErrGeneric](ctx, es.Core.UpdateByQueryRethrottle(taskID).RequestsPerSecond(rps), &rsp)
+	}), nil
+}
@@ -0,0 +1,90 @@
+package elastic_wrapper
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/refresh"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestUpdateAndDeleteByQuery(t *testing.T) {
+	es := newClient(t)
+	ctx := context.Background()
+
+	demoIndex := "test_update_delete_by_query"
+
+	t.Cleanup(func() {
+		deleted, err := es.IndexDelete(context.Background(), demoIndex)
+		if err != nil {
+			t.Fatalf("delete index failed, err=%v", err)
+		}
+		if !deleted {
+			t.Fatalf("delete index failed, deleted=%v", deleted)
+		}
+	})
+
+	rsp, err := es.IndexCreateSimple(ctx, demoIndex, &types.TypeMapping{
+		Properties: map[string]types.Property{
+			"kind":  types.NewKeywordProperty(),
+			"count": types.NewIntegerNumberProperty(),
+		},
+	})
+	if err != nil {
+		t.Fatalf("create simple index failed, err=%v", err)
+	}
+	t.Logf("rsp=%+v", rsp)
+
+	for i := 0; i < 10; i++ {
+		kind := "odd"
+		if i%2 == 0 {
+			kind = "even"
+		}
+		_, err := es.DocCreateRefresh(ctx, demoIndex, strconv.Itoa(i), map[string]any{"kind": kind, "count": i}, refresh.True)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	script := types.NewInlineScript()
+	script.Source = "ctx._source.count += 100"
+	var theScript types.Script = script
+
+	task, err := es.UpdateByQuery(ctx, demoIndex, &types.Query{Term: map[string]types.TermQuery{"kind": {Value: "even"}}}, &theScript,
+		WithConflictsProceed(), WithRequestsPerSecond(1000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("update by query task=%s", task.ID)
+
+	upRsp, err := task.WaitProgress(ctx, func(status *BulkByScrollStatus) {
+		t.Logf("progress=%d/%d batches=%d", status.Done(), status.Total, status.Batches)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if upRsp.Updated != 5 {
+		t.Fatalf("upRsp=%+v", upRsp)
+	}
+
+	// make the updated documents visible to the delete by query search
+	res, err := es.Indices.Refresh().Index(demoIndex).Do(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	task, err = es.DeleteByQuery(ctx, demoIndex, &types.Query{Range: map[string]types.RangeQuery{"count": types.NumberRangeQuery{Gte: proto.Float64(100)}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	delRsp, err := task.Wait(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if delRsp.Deleted != 5 {
+		t.Fatalf("delRsp=%+v", delRsp)
+	}
+}
@@ -22,8 +22,9 @@ func (c *CountResponse) FromJSON(bytes []byte) error {
 }
 
 // CountByIndex https://www.elastic.co/guide/en/elasticsearch/reference/current/search-count.html
-func (es *ElasticsearchEx) CountByIndex(ctx context.Context, index string, query *types.Query) (int64, error) {
-	req := es.Core.Count().Index(index)
+// supported options: WithRouting
+func (es *ElasticsearchEx) CountByIndex(ctx context.Context, index string, query *types.Query, opts ...RequestOption) (int64, error) {
+	req := applyRouting(es.Core.Count().Index(index), newRequestOptions(opts))
 	if query != nil {
 		req.Request(&count.Request{Query: query})
 	}
@@ -32,6 +33,6 @@ func (es *ElasticsearchEx) CountByIndex(ctx context.Context, index string, query
 	return rsp.Count, err
 }
 
-func (es *ElasticsearchEx) CountAllByIndex(ctx context.Context, index string) (int64, error) {
-	return es.CountByIndex(ctx, index, nil)
+func (es *ElasticsearchEx) CountAllByIndex(ctx context.Context, index string, opts ...RequestOption) (int64, error) {
+	return es.CountByIndex(ctx, index, nil, opts...)
 }
@@ -0,0 +1,61 @@
+package elastic_wrapper
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/scriptlanguage"
+
+	"github.com/ttys3/elastic-wrapper-go/scripts"
+)
+
+// CounterFieldsScript the stored script behind DocUpdateCounter.
+// The script source never changes with the fields, they are passed as params,
+// so it is compiled only once and does not hit script.max_compilations_rate
+type CounterFieldsScript struct {
+	script *types.StoredScript
+}
+
+func NewCounterFieldsScript() *CounterFieldsScript {
+	return &CounterFieldsScript{
+		script: &types.StoredScript{
+			Lang:   scriptlanguage.Painless,
+			Source: scripts.CounterScript,
+		},
+	}
+}
+
+func (s CounterFieldsScript) Script(params map[string]any) *types.Script {
+	var sc types.Script = types.StoredScriptId{
+		Params: params,
+		Id:     scripts.CounterScriptName,
+	}
+	return &sc
+}
+
+// InitScript: create script if script not found
+func (s CounterFieldsScript) InitScript(ctx context.Context, es *ElasticsearchEx) (bool, error) {
+	script := types.NewStoredScript()
+	script.Lang = s.script.Lang
+	script.Source = s.script.Source
+	resp, err := es.PutStoredScript(ctx, scripts.CounterScriptName, script)
+	if err != nil {
+		return false, err
+	}
+	return resp.Acknowledged, nil
+}
+
+var _counterScript = NewCounterFieldsScript()
+
+// RegisterCounterScript store the script behind DocUpdateCounter, it must be called once before the first counter update
+func (es *ElasticsearchEx) RegisterCounterScript(ctx context.Context) error {
+	if _, err := _counterScript.InitScript(ctx, es); err != nil {
+		return fmt.Errorf("failed to register script %s: %w", scripts.CounterScriptName, err)
+	}
+	return nil
+}
+
+func CounterParamScript(fields []scripts.CounterField) *types.Script {
+	return _counterScript.Script(map[string]interface{}{"fields": fields})
+}
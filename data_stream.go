@@ -0,0 +1,194 @@
+package elastic_wrapper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/olivere/ndjson"
+)
+
+// CreateDataStream create the data stream, a composable index template with `data_stream` enabled must match the name.
+// A data stream is also auto created by the first write if allowed by the template
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/data-streams.html
+func (es *ElasticsearchEx) CreateDataStream(ctx context.Context, name string) (*AcknowledgedResponse, error) {
+	var rsp AcknowledgedResponse
+	err := doGetResponse[ErrGeneric](ctx, es.Indices.CreateDataStream(name), &rsp)
+	return &rsp, err
+}
+
+type DataStreamInfo struct {
+	Name           string `json:"name"`
+	TimestampField struct {
+		Name string `json:"name"`
+	} `json:"timestamp_field"`
+	Indices []struct {
+		IndexName string `json:"index_name"`
+		IndexUUID string `json:"index_uuid"`
+	} `json:"indices"` // the backing indices, the last one is the write index
+	Generation         int            `json:"generation"`
+	Status             string         `json:"status"` // the health: GREEN, YELLOW or RED
+	Template           string         `json:"template"`
+	IlmPolicy          string         `json:"ilm_policy"`
+	Meta               map[string]any `json:"_meta"`
+	Hidden             bool           `json:"hidden"`
+	System             bool           `json:"system"`
+	AllowCustomRouting bool           `json:"allow_custom_routing"`
+	Replicated         bool           `json:"replicated"`
+}
+
+// WriteIndex the backing index which receives the writes
+func (d *DataStreamInfo) WriteIndex() string {
+	if len(d.Indices) == 0 {
+		return ""
+	}
+	return d.Indices[len(d.Indices)-1].IndexName
+}
+
+type GetDataStreamsResponse struct {
+	DataStreams []DataStreamInfo `json:"data_streams"`
+}
+
+func (g *GetDataStreamsResponse) FromJSON(bytes []byte) error {
+	return FromJSONImplDefault(bytes, g)
+}
+
+// GetDataStreams get the data streams by name, wildcards are supported, all data streams if no name given.
+// ErrNotFound if any of the data streams does not exist
+func (es *ElasticsearchEx) GetDataStreams(ctx context.Context, names ...string) ([]DataStreamInfo, error) {
+	req := es.Indices.GetDataStream()
+	if len(names) > 0 {
+		req.Name(strings.Join(names, ","))
+	}
+	var rsp GetDataStreamsResponse
+	err := doGetResponse[ErrGeneric](ctx, req, &rsp)
+	return rsp.DataStreams, err
+}
+
+// DeleteDataStream delete the data streams and all their backing indices, wildcards are supported
+func (es *ElasticsearchEx) DeleteDataStream(ctx context.Context, names ...string) (*AcknowledgedResponse, error) {
+	var rsp AcknowledgedResponse
+	err := doGetResponse[ErrGeneric](ctx, es.Indices.DeleteDataStream(strings.Join(names, ",")), &rsp)
+	return &rsp, err
+}
+
+// RolloverDataStream create a new backing index which becomes the write index of the data stream, see Rollover
+func (es *ElasticsearchEx) RolloverDataStream(ctx context.Context, name string) (*RolloverResponse, error) {
+	return es.Rollover(ctx, name, nil, nil)
+}
+
+// DataStreamWriter write the documents of type T into a data stream. Data streams are append-only,
+// so only `create` ops are sent, and every document must have a non-zero @timestamp which is checked
+// before anything is sent. A document implementing Document gets its own _id, else es generates one
+type DataStreamWriter[T any] struct {
+	es   *ElasticsearchEx
+	name string
+	opts []RequestOption
+}
+
+// NewDataStreamWriter supported options: WithPipeline, WithRouting (the data stream must allow custom routing)
+func NewDataStreamWriter[T any](es *ElasticsearchEx, name string, opts ...RequestOption) *DataStreamWriter[T] {
+	return &DataStreamWriter[T]{es: es, name: name, opts: opts}
+}
+
+// DataStreamWriteItem the outcome of one document
+type DataStreamWriteItem struct {
+	ID           string
+	BackingIndex string // the backing index the document landed in, e.g. .ds-metrics-2023.01.01-000001
+	Status       int
+	Error        *BulkError
+}
+
+// DataStreamWriteResult the outcome of DataStreamWriter.Write, Items are in the order of the documents
+type DataStreamWriteResult struct {
+	Items []DataStreamWriteItem
+}
+
+func (r *DataStreamWriteResult) Failed() []DataStreamWriteItem {
+	var failed []DataStreamWriteItem
+	for _, item := range r.Items {
+		if item.Error != nil {
+			failed = append(failed, item)
+		}
+	}
+	return failed
+}
+
+type dataStreamMeta struct {
+	ID       string `json:"_id,omitempty"`
+	Routing  string `json:"routing,omitempty"`
+	Pipeline string `json:"pipeline,omitempty"`
+}
+
+// Write create the documents in bulk, the result is returned together with BulkItemsError if some documents failed
+func (w *DataStreamWriter[T]) Write(ctx context.Context, docs ...T) (*DataStreamWriteResult, error) {
+	if len(docs) == 0 {
+		return &DataStreamWriteResult{}, nil
+	}
+	o := newRequestOptions(w.opts)
+	buf := bytes.NewBuffer(nil)
+	enc := ndjson.NewWriter(buf)
+	for i, doc := range docs {
+		source, err := json.Marshal(doc)
+		if err != nil {
+			return nil, fmt.Errorf("cannot encode document %d: %s", i, err)
+		}
+		if err := checkTimestamp(source); err != nil {
+			return nil, fmt.Errorf("document %d: %w", i, err)
+		}
+		meta := dataStreamMeta{Routing: o.routing, Pipeline: o.pipeline}
+		if d, ok := any(doc).(Document); ok {
+			meta.ID = d.GetID()
+		}
+		if r, ok := any(doc).(Routed); ok && r.GetRouting() != "" {
+			meta.Routing = r.GetRouting()
+		}
+		// action_and_meta_data\n
+		if err := enc.Encode(map[string]dataStreamMeta{"create": meta}); err != nil {
+			return nil, fmt.Errorf("cannot encode action_and_meta_data %d: %s", i, err)
+		}
+		// source\n
+		if err := enc.Encode(json.RawMessage(source)); err != nil {
+			return nil, fmt.Errorf("cannot encode document %d: %s", i, err)
+		}
+	}
+
+	rsp, err := w.es.doBulkResponse(ctx, w.name, buf.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	result := &DataStreamWriteResult{Items: make([]DataStreamWriteItem, 0, len(rsp.Items))}
+	var failed []BulkResponseItem
+	for _, item := range rsp.Items {
+		result.Items = append(result.Items, DataStreamWriteItem{
+			ID:           item.Detail.ID,
+			BackingIndex: item.Detail.Index,
+			Status:       item.Detail.Status,
+			Error:        item.Detail.Error,
+		})
+		if item.Detail.Error != nil {
+			failed = append(failed, item)
+		}
+	}
+	if len(failed) > 0 {
+		return result, BulkItemsError{Items: failed}
+	}
+	return result, nil
+}
+
+// checkTimestamp the JSON document must have a non-empty @timestamp, the zero time.Time is rejected too
+func checkTimestamp(source []byte) error {
+	var probe struct {
+		Timestamp json.RawMessage `json:"@timestamp"`
+	}
+	if err := json.Unmarshal(source, &probe); err != nil {
+		return err
+	}
+	switch string(probe.Timestamp) {
+	case "", "null", `""`, `"0001-01-01T00:00:00Z"`:
+		return ErrMissingTimestamp
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package elastic_wrapper
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+)
+
+type metricPoint struct {
+	Timestamp time.Time `json:"@timestamp"`
+	Host      string    `json:"host"`
+	CPU       float64   `json:"cpu"`
+}
+
+func TestCheckTimestamp(t *testing.T) {
+	for source, valid := range map[string]bool{
+		`{"@timestamp": "2023-01-01T00:00:00Z"}`: true,
+		`{"@timestamp": 1672531200000}`:          true,
+		`{"@timestamp": "0001-01-01T00:00:00Z"}`: false,
+		`{"@timestamp": ""}`:                     false,
+		`{"@timestamp": null}`:                   false,
+		`{"host": "a"}`:                          false,
+	} {
+		err := checkTimestamp([]byte(source))
+		if valid && err != nil {
+			t.Errorf("%s: expect valid, err=%v", source, err)
+		}
+		if !valid && !errors.Is(err, ErrMissingTimestamp) {
+			t.Errorf("%s: expect ErrMissingTimestamp, err=%v", source, err)
+		}
+	}
+}
+
+func TestDataStreamWriter(t *testing.T) {
+	es := newClient(t)
+
+	templateName := "test_metrics"
+	dataStream := "test_metrics-cpu"
+
+	t.Cleanup(func() {
+		if _, err := es.DeleteDataStream(context.Background(), dataStream); err != nil {
+			t.Fatalf("delete data stream failed, err=%v", err)
+		}
+		if _, err := es.DeleteIndexTemplate(context.Background(), templateName); err != nil {
+			t.Fatalf("delete index template failed, err=%v", err)
+		}
+	})
+
+	priority := 100
+	if _, err := es.PutIndexTemplate(context.Background(), templateName, &IndexTemplate{
+		IndexPatterns: []string{"test_metrics-*"},
+		DataStream:    &types.DataStreamVisibility{},
+		Priority:      &priority,
+	}); err != nil {
+		t.Fatalf("put index template failed, err=%v", err)
+	}
+	if _, err := es.CreateDataStream(context.Background(), dataStream); err != nil {
+		t.Fatalf("create data stream failed, err=%v", err)
+	}
+
+	w := NewDataStreamWriter[metricPoint](es, dataStream)
+	if _, err := w.Write(context.Background(), metricPoint{Host: "a"}); !errors.Is(err, ErrMissingTimestamp) {
+		t.Fatalf("expect ErrMissingTimestamp, err=%v", err)
+	}
+	result, err := w.Write(context.Background(),
+		metricPoint{Timestamp: time.Now(), Host: "a", CPU: 0.5},
+		metricPoint{Timestamp: time.Now(), Host: "b", CPU: 0.7},
+	)
+	if err != nil {
+		t.Fatalf("write data stream failed, err=%v", err)
+	}
+	for _, item := range result.Items {
+		t.Logf("item=%+v", item)
+		if !strings.HasPrefix(item.BackingIndex, ".ds-"+dataStream) {
+			t.Fatalf("unexpected backing index %s", item.BackingIndex)
+		}
+	}
+
+	rolled, err := es.RolloverDataStream(context.Background(), dataStream)
+	if err != nil {
+		t.Fatalf("rollover data stream failed, err=%v", err)
+	}
+	streams, err := es.GetDataStreams(context.Background(), dataStream)
+	if err != nil {
+		t.Fatalf("get data streams failed, err=%v", err)
+	}
+	if len(streams) != 1 || streams[0].WriteIndex() != rolled.NewIndex || streams[0].Generation != 2 {
+		t.Fatalf("unexpected data streams %+v, rollover=%+v", streams, rolled)
+	}
+}
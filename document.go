@@ -3,16 +3,16 @@ package elastic_wrapper
 import (
 	"context"
 	"fmt"
-	"strings"
-	"text/template"
+	"net/http"
 
 	"github.com/elastic/go-elasticsearch/v8/typedapi/core/create"
 	"github.com/elastic/go-elasticsearch/v8/typedapi/core/index"
 	"github.com/elastic/go-elasticsearch/v8/typedapi/core/update"
 	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
 	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/refresh"
-	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/scriptlanguage"
 	"google.golang.org/protobuf/proto"
+
+	"github.com/ttys3/elastic-wrapper-go/scripts"
 )
 
 type ErrorDocIndex struct {
@@ -68,18 +68,33 @@ func (es *ElasticsearchEx) DocCreate(ctx context.Context, docCreate *create.Crea
 	return &rsp, err
 }
 
-func (es *ElasticsearchEx) DocCreateSimple(ctx context.Context, index, id string, body interface{}) (*DocCreateResponse, error) {
-	return es.DocCreate(ctx, es.Create(index, id).Request(body))
+// DocCreateSimple supported options: WithPipeline, WithRouting.
+// es does not support versioning on create, the version options return ErrCreateVersioning,
+// use DocIndexSimple with WithExternalVersion instead
+func (es *ElasticsearchEx) DocCreateSimple(ctx context.Context, index, id string, body interface{}, opts ...RequestOption) (*DocCreateResponse, error) {
+	req, err := applyCreateOptions(es.Create(index, id).Request(body), newRequestOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+	return es.DocCreate(ctx, req)
 }
 
 // DocCreateRefresh https://www.elastic.co/guide/en/elasticsearch/reference/current/docs-refresh.html
 // This should ONLY be done after careful thought and verification that it does not lead to poor performance, both from an indexing and a search standpoint.
-func (es *ElasticsearchEx) DocCreateRefresh(ctx context.Context, index, id string, body interface{}, r refresh.Refresh) (*DocCreateResponse, error) {
-	return es.DocCreate(ctx, es.Create(index, id).Request(body).Refresh(r))
+func (es *ElasticsearchEx) DocCreateRefresh(ctx context.Context, index, id string, body interface{}, r refresh.Refresh, opts ...RequestOption) (*DocCreateResponse, error) {
+	req, err := applyCreateOptions(es.Create(index, id).Request(body).Refresh(r), newRequestOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+	return es.DocCreate(ctx, req)
 }
 
-func (es *ElasticsearchEx) DocCreateRaw(ctx context.Context, index, id string, body []byte) (*DocCreateResponse, error) {
-	return es.DocCreate(ctx, es.Create(index, id).Raw(body))
+func (es *ElasticsearchEx) DocCreateRaw(ctx context.Context, index, id string, body []byte, opts ...RequestOption) (*DocCreateResponse, error) {
+	req, err := applyCreateOptions(es.Create(index, id).Raw(body), newRequestOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+	return es.DocCreate(ctx, req)
 }
 
 // DocIndex Creates or updates a document in an index
@@ -90,8 +105,16 @@ func (es *ElasticsearchEx) DocIndex(ctx context.Context, docIndex *index.Index)
 	return &rsp, err
 }
 
-func (es *ElasticsearchEx) DocIndexSimple(ctx context.Context, index, id string, body interface{}) (*DocCreateResponse, error) {
-	return es.DocIndex(ctx, es.Index(index).Request(body).Id(id))
+// DocIndexSimple supported options: WithVersion, WithVersionType, WithExternalVersion, WithExternalGteVersion, WithPipeline, WithRouting
+// returns ErrStaleVersion if the versioned write is rejected
+func (es *ElasticsearchEx) DocIndexSimple(ctx context.Context, index, id string, body interface{}, opts ...RequestOption) (*DocCreateResponse, error) {
+	o := newRequestOptions(opts)
+	req, err := applyIndexOptions(es.Index(index).Request(body).Id(id), o)
+	if err != nil {
+		return nil, err
+	}
+	rsp, err := es.DocIndex(ctx, req)
+	return rsp, o.versionConflictErr(err)
 }
 
 type ErrorDocGet struct{}
@@ -109,6 +132,9 @@ type DocGetResponse[S any] struct {
 	PrimaryTerm int    `json:"_primary_term"`
 	Found       bool   `json:"found"`
 	Source      S      `json:"_source"`
+	Routing     string `json:"_routing"`
+	// Fields only returned when stored fields are requested with WithStoredFields
+	Fields map[string]any `json:"fields"`
 }
 
 // FromJSON impl FromJSON for DocGetResponse
@@ -128,9 +154,43 @@ func (e ErrGetDoc) Error() string {
 }
 
 // DocGet get document by id
-// 404 if doc not found
-func (es *ElasticsearchEx) DocGet(ctx context.Context, index, id string, result FromJSON) error {
-	return doGetResponse[ErrGetDoc](ctx, es.Get(index, id), result)
+// 404 if doc not found, 409 if WithVersion is given and the version does not match
+// supported options: WithSource, WithSourceIncludes, WithSourceExcludes, WithStoredFields, WithRouting,
+// WithPreference, WithRealtime, WithRefreshBeforeGet, WithVersion, WithVersionType
+func (es *ElasticsearchEx) DocGet(ctx context.Context, index, id string, result FromJSON, opts ...RequestOption) error {
+	req := applyGetOptions(es.Get(index, id), newRequestOptions(opts))
+	return doGetResponse[ErrGetDoc](ctx, req, result)
+}
+
+// DocGetSource get only the _source of the document by id using the `_source` endpoint
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/docs-get.html#_source
+// accepts the same options as DocGet
+func DocGetSource[T any](ctx context.Context, es *ElasticsearchEx, index, id string, opts ...RequestOption) (*T, error) {
+	req := applyGetOptions(es.GetSource(index, id), newRequestOptions(opts))
+	var source T
+	err := doGetResponse[ErrGetDoc](ctx, req, &typedResponse[T]{v: &source})
+	if err != nil {
+		return nil, err
+	}
+	return &source, nil
+}
+
+// DocExists check whether the document exists with a HEAD request, the document body is never downloaded
+// accepts the same options as DocGet
+func (es *ElasticsearchEx) DocExists(ctx context.Context, index, id string, opts ...RequestOption) (bool, error) {
+	req := applyGetOptions(es.Exists(index, id), newRequestOptions(opts))
+	res, err := req.Do(ctx)
+	defer bodyClose(res)
+	if err != nil {
+		return false, err
+	}
+	if res.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if err := NewResponseStatusError(res.StatusCode); err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
 type DocDeleteResponse struct {
@@ -153,10 +213,17 @@ func (d *DocDeleteResponse) FromJSON(bytes []byte) error {
 }
 
 // DocDelete delete document by id
-func (es *ElasticsearchEx) DocDelete(ctx context.Context, index, id string) (*DocDeleteResponse, error) {
+// supported options: WithVersion, WithVersionType, WithExternalVersion, WithExternalGteVersion, WithRouting
+// returns ErrStaleVersion if the versioned delete is rejected
+func (es *ElasticsearchEx) DocDelete(ctx context.Context, index, id string, opts ...RequestOption) (*DocDeleteResponse, error) {
+	o := newRequestOptions(opts)
+	req, err := applyWriteOptions(es.Delete(index, id), o)
+	if err != nil {
+		return nil, err
+	}
 	var rsp DocDeleteResponse
-	err := doGetResponse[ErrorDocGet](ctx, es.Delete(index, id), &rsp)
-	return &rsp, err
+	err = doGetResponse[ErrorDocGet](ctx, req, &rsp)
+	return &rsp, o.versionConflictErr(err)
 }
 
 type ErrorDocUpdate struct {
@@ -193,12 +260,40 @@ func (e ErrorDocUpdate) Error() string {
 		e.Status, e.TheError.Reason, e.TheError.CausedBy, position, e.TheError.CausedBy.ScriptStack)
 }
 
-type DocUpdateResponse struct{}
+type DocUpdateResponse struct {
+	Index   string `json:"_index"`
+	Type    string `json:"_type"`
+	Id      string `json:"_id"`
+	Version int    `json:"_version"`
+	Result  string `json:"result"` // one of created, updated, noop, deleted
+	Shards  struct {
+		Total      int `json:"total"`
+		Successful int `json:"successful"`
+		Failed     int `json:"failed"`
+	} `json:"_shards"`
+	SeqNo       int `json:"_seq_no"`
+	PrimaryTerm int `json:"_primary_term"`
+}
 
 func (d *DocUpdateResponse) FromJSON(bytes []byte) error {
 	return FromJSONImplDefault(bytes, d)
 }
 
+// DocUpdateReturningResponse the update response with the updated document in Get
+type DocUpdateReturningResponse[T any] struct {
+	DocUpdateResponse
+	Get struct {
+		SeqNo       int  `json:"_seq_no"`
+		PrimaryTerm int  `json:"_primary_term"`
+		Found       bool `json:"found"`
+		Source      T    `json:"_source"`
+	} `json:"get"`
+}
+
+func (d *DocUpdateReturningResponse[T]) FromJSON(bytes []byte) error {
+	return FromJSONImplDefault(bytes, d)
+}
+
 // DocUpdate update document by id
 // using DocIndex if you want to do full update (which is actually reindex)
 // https://www.elastic.co/guide/en/elasticsearch/reference/current/docs-update.html
@@ -207,34 +302,49 @@ func (d *DocUpdateResponse) FromJSON(bytes []byte) error {
 // To fully replace an existing document, use the index API.
 // The _source field must be enabled to use update. In addition to _source, you can access the following variables
 // through the ctx map: _index, _type, _id, _version, _routing, and _now (the current timestamp).
-func (es *ElasticsearchEx) DocUpdate(ctx context.Context, index, id string, updateReq *update.Request) (*DocUpdateResponse, error) {
+// supported options: WithRouting, the same applies to all the DocUpdate* and DocUpsert* functions
+func (es *ElasticsearchEx) DocUpdate(ctx context.Context, index, id string, updateReq *update.Request, opts ...RequestOption) (*DocUpdateResponse, error) {
 	var rsp DocUpdateResponse
-	err := doGetResponse[ErrorDocUpdate](ctx, es.Update(index, id).Request(updateReq), &rsp)
+	req := applyRouting(es.Update(index, id).Request(updateReq), newRequestOptions(opts))
+	err := doGetResponse[ErrorDocUpdate](ctx, req, &rsp)
 	return &rsp, err
 }
 
-func (es *ElasticsearchEx) DocUpdateRetryOnConflict(ctx context.Context, index, id string, updateReq *update.Request) (*DocUpdateResponse, error) {
+func (es *ElasticsearchEx) DocUpdateRetryOnConflict(ctx context.Context, index, id string, updateReq *update.Request, opts ...RequestOption) (*DocUpdateResponse, error) {
 	var rsp DocUpdateResponse
-	err := doGetResponse[ErrorDocUpdate](ctx, es.Update(index, id).Request(updateReq).RetryOnConflict(3), &rsp)
+	req := applyRouting(es.Update(index, id).Request(updateReq).RetryOnConflict(3), newRequestOptions(opts))
+	err := doGetResponse[ErrorDocUpdate](ctx, req, &rsp)
+	return &rsp, err
+}
+
+// DocUpdateReturning update document by id and return the updated _source decoded into T
+// the update request is sent with `"_source": true`, the document is read from `get._source` in the response
+func DocUpdateReturning[T any](ctx context.Context, es *ElasticsearchEx, index, id string, updateReq *update.Request, opts ...RequestOption) (*DocUpdateReturningResponse[T], error) {
+	// a shallow copy, the request of the caller is left untouched and can be reused
+	returning := *updateReq
+	var source types.SourceConfig = true
+	returning.Source_ = &source
+	var rsp DocUpdateReturningResponse[T]
+	req := applyRouting(es.Update(index, id).Request(&returning), newRequestOptions(opts))
+	err := doGetResponse[ErrorDocUpdate](ctx, req, &rsp)
 	return &rsp, err
 }
 
 // DocUpdateSimple A partial update to an existing document by id
-func (es *ElasticsearchEx) DocUpdateSimple(ctx context.Context, index, id string, doc interface{}) (*DocUpdateResponse, error) {
-	return es.DocUpdate(ctx, index, id, &update.Request{Doc: doc})
+func (es *ElasticsearchEx) DocUpdateSimple(ctx context.Context, index, id string, doc interface{}, opts ...RequestOption) (*DocUpdateResponse, error) {
+	return es.DocUpdate(ctx, index, id, &update.Request{Doc: doc}, opts...)
 }
 
-func (es *ElasticsearchEx) DocUpdateSimpleRetryOnConflict(ctx context.Context, index, id string, doc interface{}) (*DocUpdateResponse, error) {
-	return es.DocUpdateRetryOnConflict(ctx, index, id, &update.Request{Doc: doc})
+func (es *ElasticsearchEx) DocUpdateSimpleRetryOnConflict(ctx context.Context, index, id string, doc interface{}, opts ...RequestOption) (*DocUpdateResponse, error) {
+	return es.DocUpdateRetryOnConflict(ctx, index, id, &update.Request{Doc: doc}, opts...)
 }
 
 // DocUpdateScript A partial update to an existing document by using scripts
 // TODO using stored script https://www.elastic.co/guide/en/elasticsearch/reference/7.17/modules-scripting-using.html#script-stored-scripts
 // https://www.elastic.co/guide/en/elasticsearch/reference/current/modules-scripting-using.html#script-stored-scripts
 // https://www.elastic.co/guide/en/elasticsearch/painless/7.17/painless-execute-api.html#painless-execute-api-request-body
-func (es *ElasticsearchEx) DocUpdateScript(ctx context.Context, index, id, source string, params map[string]interface{}) (*DocUpdateResponse, error) {
-	// DocAsUpsert?
-	// ScriptedUpsert ?
+// using DocUpsertWithScript if the document should be created when missing
+func (es *ElasticsearchEx) DocUpdateScript(ctx context.Context, index, id, source string, params map[string]interface{}, opts ...RequestOption) (*DocUpdateResponse, error) {
 	script := types.NewInlineScript()
 	script.Source = source
 	if len(params) > 0 {
@@ -242,65 +352,74 @@ func (es *ElasticsearchEx) DocUpdateScript(ctx context.Context, index, id, sourc
 	}
 
 	ts := types.Script(script)
-	return es.DocUpdate(ctx, index, id, &update.Request{Script: &ts})
+	return es.DocUpdate(ctx, index, id, &update.Request{Script: &ts}, opts...)
 }
 
-var counterTmpl = `
-if (ctx._source.{{.FieldName}} == null) {
-	ctx._source.{{.FieldName}} = params.{{.CountParamName}};
-} else {
-	ctx._source.{{.FieldName}} += params.{{.CountParamName}};
+// counterUpdateRequest
+// Upsert: [UpdateRequest] upsert doesn't support values of type: VALUE_BOOLEAN
+// DocAsUpsert: Validation Failed: 1: doc must be specified if doc_as_upsert is enabled
+// so it is a scripted upsert starting from an empty document
+func counterUpdateRequest(fields []scripts.CounterField) *update.Request {
+	updateReq := update.NewRequest()
+	updateReq.Script = CounterParamScript(fields)
+	updateReq.ScriptedUpsert = proto.Bool(true)
+	updateReq.Upsert = map[string]any{}
+	return updateReq
 }
-`
-
-var counterTemplate = template.Must(template.New("counter_tmpl").Parse(counterTmpl))
 
-func (es *ElasticsearchEx) DocUpdateCounter(ctx context.Context, index, id string, fieldsIncrMap map[string]int64) (*DocUpdateResponse, error) {
-	// DocAsUpsert?
-	// ScriptedUpsert ?
-
-	// POST my-index-000001/_update/1
-	// {
-	//	"script" : {
-	//	"source": "ctx._source.counter += params.count",
-	//		"lang": "painless",
-	//		"params" : {
-	//		"count" : 4
-	//	}
-	// }
-	// }
-
-	params := make(map[string]interface{})
-	var sb strings.Builder
+func counterFields(fieldsIncrMap map[string]int64) []scripts.CounterField {
+	fields := make([]scripts.CounterField, 0, len(fieldsIncrMap))
 	for field, incr := range fieldsIncrMap {
-		countParamName := fmt.Sprintf("count_%s", field)
-		// sb.WriteString(fmt.Sprintf("ctx._source.%s += params.%s;", field, countParamName))
-		counterTemplate.Execute(&sb, map[string]interface{}{
-			"FieldName":      field,
-			"CountParamName": countParamName,
-		})
-		params[countParamName] = incr
+		fields = append(fields, scripts.IncrBy(field, incr))
 	}
+	return fields
+}
 
-	script := types.NewInlineScript()
-	script.Source = sb.String()
-	script.Lang = &scriptlanguage.Painless
-	script.Params = params
-	theScript := types.Script(script)
+// DocUpdateCounter increase the counter fields by a stored script, the document is created if not exists.
+// The script is registered by RegisterCounterScript
+// POST my-index-000001/_update/1
+//
+//	{
+//	  "script" : {
+//	    "id": "<scripts.CounterScriptName>",
+//	    "params" : {
+//	      "fields" : [{"name": "counter", "delta": 4}]
+//	    }
+//	  }
+//	}
+func (es *ElasticsearchEx) DocUpdateCounter(ctx context.Context, index, id string, fieldsIncrMap map[string]int64, opts ...RequestOption) (*DocUpdateResponse, error) {
+	return es.DocUpdate(ctx, index, id, counterUpdateRequest(counterFields(fieldsIncrMap)), opts...)
+}
 
-	updateReq := update.NewRequest()
-	updateReq.Script = &theScript
-	updateReq.ScriptedUpsert = proto.Bool(true)
-	// Upsert: [UpdateRequest] upsert doesn't support values of type: VALUE_BOOLEAN
-	// DocAsUpsert: Validation Failed: 1: doc must be specified if doc_as_upsert is enabled
-	return es.DocUpdate(ctx, index, id, updateReq)
+// DocUpdateCounterFields like DocUpdateCounter, with float deltas, min/max clamping and dotted nested field names
+// e.g. es.DocUpdateCounterFields(ctx, index, id, scripts.IncrBy("stats.views", 1), scripts.IncrByFloat("score", -0.5).WithMin(0))
+func (es *ElasticsearchEx) DocUpdateCounterFields(ctx context.Context, index, id string, fields ...scripts.CounterField) (*DocUpdateResponse, error) {
+	return es.DocUpdate(ctx, index, id, counterUpdateRequest(fields))
+}
+
+// DocUpdateCounterFieldsRouted same as DocUpdateCounterFields, for documents with a custom routing
+func (es *ElasticsearchEx) DocUpdateCounterFieldsRouted(ctx context.Context, index, id, routing string, fields ...scripts.CounterField) (*DocUpdateResponse, error) {
+	return es.DocUpdate(ctx, index, id, counterUpdateRequest(fields), WithRouting(routing))
+}
+
+// DocUpdateCounterReturning same as DocUpdateCounter, but returns the updated document, no second GET needed
+func DocUpdateCounterReturning[T any](ctx context.Context, es *ElasticsearchEx, index, id string, fieldsIncrMap map[string]int64, opts ...RequestOption) (*DocUpdateReturningResponse[T], error) {
+	return DocUpdateReturning[T](ctx, es, index, id, counterUpdateRequest(counterFields(fieldsIncrMap)), opts...)
 }
 
 func (es *ElasticsearchEx) DocUpdateCounterSimple(ctx context.Context, index, id string, delta int64, fields ...string) (*DocUpdateResponse, error) {
+	return es.DocUpdateCounter(ctx, index, id, counterSimpleIncrMap(delta, fields))
+}
+
+// DocUpdateCounterSimpleRouted same as DocUpdateCounterSimple, for documents with a custom routing
+func (es *ElasticsearchEx) DocUpdateCounterSimpleRouted(ctx context.Context, index, id, routing string, delta int64, fields ...string) (*DocUpdateResponse, error) {
+	return es.DocUpdateCounter(ctx, index, id, counterSimpleIncrMap(delta, fields), WithRouting(routing))
+}
+
+func counterSimpleIncrMap(delta int64, fields []string) map[string]int64 {
 	fieldsIncrMap := make(map[string]int64)
 	for _, field := range fields {
 		fieldsIncrMap[field] = delta
 	}
-
-	return es.DocUpdateCounter(ctx, index, id, fieldsIncrMap)
+	return fieldsIncrMap
 }
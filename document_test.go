@@ -5,7 +5,10 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/elastic/go-elasticsearch/v8/typedapi/core/update"
 	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+
+	"github.com/ttys3/elastic-wrapper-go/scripts"
 )
 
 // test document create
@@ -186,6 +189,9 @@ func TestDocUpdateNotFound(t *testing.T) {
 // test DocUpdateCounter
 func TestDocUpdateCounter(t *testing.T) {
 	es := newClient(t)
+	if err := es.RegisterCounterScript(context.Background()); err != nil {
+		t.Fatalf("register counter script failed, err=%v", err)
+	}
 
 	demoIndex := "test_doc_update_counter_simple"
 
@@ -264,6 +270,9 @@ func TestDocUpdateCounter(t *testing.T) {
 // test DocUpdateCounterSimple
 func TestDocUpdateCounterSimple(t *testing.T) {
 	es := newClient(t)
+	if err := es.RegisterCounterScript(context.Background()); err != nil {
+		t.Fatalf("register counter script failed, err=%v", err)
+	}
 
 	demoIndex := "test_doc_update_counter_simple"
 
@@ -335,6 +344,9 @@ func TestDocUpdateCounterSimple(t *testing.T) {
 
 func TestDocUpdateCounterSimpleNoInitValueFields(t *testing.T) {
 	es := newClient(t)
+	if err := es.RegisterCounterScript(context.Background()); err != nil {
+		t.Fatalf("register counter script failed, err=%v", err)
+	}
 
 	demoIndex := "test_doc_update_counter_simple_no_init_value_fields"
 
@@ -412,6 +424,9 @@ func TestDocUpdateCounterSimpleNoInitValueFields(t *testing.T) {
 
 func TestDocUpdateCounterSimpleNoInitValueFieldsWithOmitempty(t *testing.T) {
 	es := newClient(t)
+	if err := es.RegisterCounterScript(context.Background()); err != nil {
+		t.Fatalf("register counter script failed, err=%v", err)
+	}
 
 	demoIndex := "test_doc_update_counter_simple_no_init_value_fields"
 
@@ -486,3 +501,290 @@ func TestDocUpdateCounterSimpleNoInitValueFieldsWithOmitempty(t *testing.T) {
 		t.Fatalf("docGetRsp.Source.CommentCount=%d", docGetRsp.Source.CommentCount)
 	}
 }
+
+// test DocGet options, DocGetSource and DocExists
+func TestDocGetOptions(t *testing.T) {
+	es := newClient(t)
+
+	demoIndex := "test_doc_get_options"
+
+	t.Cleanup(func() {
+		deleted, err := es.IndexDelete(context.Background(), demoIndex)
+		if err != nil {
+			t.Fatalf("delete index failed, err=%v", err)
+		}
+		if !deleted {
+			t.Fatalf("delete index failed, deleted=%v", deleted)
+		}
+	})
+
+	rsp, err := es.IndexCreateSimple(context.Background(), demoIndex, &types.TypeMapping{
+		Properties: map[string]types.Property{
+			"title":   types.NewKeywordProperty(),
+			"content": types.NewKeywordProperty(),
+		},
+	})
+	if err != nil {
+		t.Fatalf("create simple index failed, err=%v", err)
+	}
+	t.Logf("rsp=%+v", rsp)
+
+	type DemoDoc struct {
+		Title   string `json:"title"`
+		Content string `json:"content"`
+	}
+
+	docRsp, err := es.DocCreateSimple(context.Background(), demoIndex, "1", &DemoDoc{Title: "hello", Content: "world"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("docRsp=%+v", docRsp)
+
+	// source filtering
+	var docGetRsp DocGetResponse[DemoDoc]
+	err = es.DocGet(context.Background(), demoIndex, "1", &docGetRsp, WithSourceIncludes("title"), WithRealtime(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("docGetRsp=%+v", docGetRsp)
+	if docGetRsp.Source.Title != "hello" || docGetRsp.Source.Content != "" {
+		t.Fatalf("source filtering not work, docGetRsp.Source=%+v", docGetRsp.Source)
+	}
+
+	// version check
+	var docGetConflictRsp DocGetResponse[DemoDoc]
+	err = es.DocGet(context.Background(), demoIndex, "1", &docGetConflictRsp, WithVersion(int64(docRsp.Version)+1))
+	if !errors.Is(err, ErrConflict) {
+		t.Fatalf("get with mismatched version should conflict, err=%v", err)
+	}
+
+	source, err := DocGetSource[DemoDoc](context.Background(), es, demoIndex, "1", WithSourceExcludes("title"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("source=%+v", source)
+	if source.Title != "" || source.Content != "world" {
+		t.Fatalf("source filtering not work, source=%+v", source)
+	}
+
+	exists, err := es.DocExists(context.Background(), demoIndex, "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !exists {
+		t.Fatalf("document 1 should exists")
+	}
+
+	exists, err = es.DocExists(context.Background(), demoIndex, "2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exists {
+		t.Fatalf("document 2 should not exists")
+	}
+}
+
+// test DocUpdateReturning and DocUpdateCounterReturning
+func TestDocUpdateReturning(t *testing.T) {
+	es := newClient(t)
+	if err := es.RegisterCounterScript(context.Background()); err != nil {
+		t.Fatalf("register counter script failed, err=%v", err)
+	}
+
+	demoIndex := "test_doc_update_returning"
+
+	t.Cleanup(func() {
+		deleted, err := es.IndexDelete(context.Background(), demoIndex)
+		if err != nil {
+			t.Fatalf("delete index failed, err=%v", err)
+		}
+		if !deleted {
+			t.Fatalf("delete index failed, deleted=%v", deleted)
+		}
+	})
+
+	rsp, err := es.IndexCreateSimple(context.Background(), demoIndex, &types.TypeMapping{
+		Properties: map[string]types.Property{
+			"content":    types.NewKeywordProperty(),
+			"like_count": types.NewIntegerNumberProperty(),
+		},
+	})
+	if err != nil {
+		t.Fatalf("create simple index failed, err=%v", err)
+	}
+	t.Logf("rsp=%+v", rsp)
+
+	type DemoDoc struct {
+		Content   string `json:"content"`
+		LikeCount int    `json:"like_count"`
+	}
+
+	docRsp, err := es.DocCreateSimple(context.Background(), demoIndex, "1", &DemoDoc{Content: "hello", LikeCount: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("docRsp=%+v", docRsp)
+
+	upRsp, err := DocUpdateReturning[DemoDoc](context.Background(), es, demoIndex, "1", &update.Request{Doc: map[string]any{"content": "world"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("upRsp=%+v", upRsp)
+	if upRsp.Result != "updated" || upRsp.Version != 2 {
+		t.Fatalf("upRsp=%+v", upRsp)
+	}
+	if upRsp.Get.Source.Content != "world" || upRsp.Get.Source.LikeCount != 1 {
+		t.Fatalf("upRsp.Get.Source=%+v", upRsp.Get.Source)
+	}
+
+	ctUpRsp, err := DocUpdateCounterReturning[DemoDoc](context.Background(), es, demoIndex, "1", map[string]int64{"like_count": 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("ctUpRsp=%+v", ctUpRsp)
+	if ctUpRsp.Get.Source.LikeCount != 3 {
+		t.Fatalf("ctUpRsp.Get.Source.LikeCount=%d", ctUpRsp.Get.Source.LikeCount)
+	}
+}
+
+// test DocUpdateCounterFields with float delta, clamping and nested fields
+func TestDocUpdateCounterFields(t *testing.T) {
+	es := newClient(t)
+	if err := es.RegisterCounterScript(context.Background()); err != nil {
+		t.Fatalf("register counter script failed, err=%v", err)
+	}
+
+	demoIndex := "test_doc_update_counter_fields"
+
+	t.Cleanup(func() {
+		deleted, err := es.IndexDelete(context.Background(), demoIndex)
+		if err != nil {
+			t.Fatalf("delete index failed, err=%v", err)
+		}
+		if !deleted {
+			t.Fatalf("delete index failed, deleted=%v", deleted)
+		}
+	})
+
+	rsp, err := es.IndexCreateSimple(context.Background(), demoIndex, &types.TypeMapping{
+		Properties: map[string]types.Property{
+			"score": types.NewDoubleNumberProperty(),
+			"stock": types.NewIntegerNumberProperty(),
+		},
+	})
+	if err != nil {
+		t.Fatalf("create simple index failed, err=%v", err)
+	}
+	t.Logf("rsp=%+v", rsp)
+
+	type DemoDoc struct {
+		Score float64 `json:"score"`
+		Stock int     `json:"stock"`
+		Stats struct {
+			Views int `json:"views"`
+		} `json:"stats"`
+	}
+
+	// the document does not exist yet, it should be created
+	ctUpRsp, err := es.DocUpdateCounterFields(context.Background(), demoIndex, "1",
+		scripts.IncrByFloat("score", 1.5),
+		scripts.IncrBy("stock", 3),
+		scripts.IncrBy("stats.views", 1),
+	)
+	if err != nil {
+		t.Fatalf("ctUpErr=%+v", err)
+	}
+	t.Logf("ctUpRsp=%+v", ctUpRsp)
+
+	ctUpRsp, err = es.DocUpdateCounterFields(context.Background(), demoIndex, "1",
+		scripts.IncrByFloat("score", 0.25).WithMax(1.6),
+		// a fractional bound keeps the integer counter an integer
+		scripts.IncrBy("stock", -5).WithMin(0.5),
+		scripts.IncrBy("stats.views", 1),
+	)
+	if err != nil {
+		t.Fatalf("ctUpErr=%+v", err)
+	}
+	t.Logf("ctUpRsp=%+v", ctUpRsp)
+
+	var docGetRsp DocGetResponse[DemoDoc]
+	err = es.DocGet(context.Background(), demoIndex, "1", &docGetRsp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("docGetRsp=%+v", docGetRsp)
+	if docGetRsp.Source.Score != 1.6 {
+		t.Fatalf("docGetRsp.Source.Score=%v", docGetRsp.Source.Score)
+	}
+	if docGetRsp.Source.Stock != 1 {
+		t.Fatalf("docGetRsp.Source.Stock=%v", docGetRsp.Source.Stock)
+	}
+	if docGetRsp.Source.Stats.Views != 2 {
+		t.Fatalf("docGetRsp.Source.Stats.Views=%v", docGetRsp.Source.Stats.Views)
+	}
+}
+
+// test external versioning of document writes
+func TestDocIndexExternalVersion(t *testing.T) {
+	es := newClient(t)
+
+	demoIndex := "test_doc_index_external_version"
+
+	t.Cleanup(func() {
+		deleted, err := es.IndexDelete(context.Background(), demoIndex)
+		if err != nil {
+			t.Fatalf("delete index failed, err=%v", err)
+		}
+		if !deleted {
+			t.Fatalf("delete index failed, deleted=%v", deleted)
+		}
+	})
+
+	rsp, err := es.IndexCreateSimple(context.Background(), demoIndex, &types.TypeMapping{
+		Properties: map[string]types.Property{
+			"content": types.NewKeywordProperty(),
+		},
+	})
+	if err != nil {
+		t.Fatalf("create simple index failed, err=%v", err)
+	}
+	t.Logf("rsp=%+v", rsp)
+
+	docRsp, err := es.DocIndexSimple(context.Background(), demoIndex, "1", map[string]any{"content": "v10"}, WithExternalVersion(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if docRsp.Version != 10 {
+		t.Fatalf("docRsp=%+v", docRsp)
+	}
+
+	_, err = es.DocIndexSimple(context.Background(), demoIndex, "1", map[string]any{"content": "v9"}, WithExternalVersion(9))
+	if !errors.Is(err, ErrStaleVersion) {
+		t.Fatalf("older version should be stale, err=%v", err)
+	}
+
+	_, err = es.DocCreateSimple(context.Background(), demoIndex, "2", map[string]any{"content": "v1"}, WithExternalVersion(1))
+	if !errors.Is(err, ErrCreateVersioning) {
+		t.Fatalf("versioned create should be rejected, err=%v", err)
+	}
+
+	_, err = es.DocIndexSimple(context.Background(), demoIndex, "1", map[string]any{"content": "v11"}, WithVersion(11))
+	if !errors.Is(err, ErrVersionWithoutType) {
+		t.Fatalf("version without a version type should be rejected, err=%v", err)
+	}
+
+	_, err = es.DocIndexSimple(context.Background(), demoIndex, "1", map[string]any{"content": "v10 again"}, WithExternalGteVersion(10))
+	if err != nil {
+		t.Fatalf("the same version should be accepted by external_gte, err=%v", err)
+	}
+
+	_, err = es.DocDelete(context.Background(), demoIndex, "1", WithExternalVersion(10))
+	if !errors.Is(err, ErrStaleVersion) {
+		t.Fatalf("delete with the same version should be stale, err=%v", err)
+	}
+
+	_, err = es.DocDelete(context.Background(), demoIndex, "1", WithExternalVersion(11))
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	elasticsearchv8 "github.com/elastic/go-elasticsearch/v8"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
 )
 
 type ElasticsearchEx struct {
@@ -21,7 +22,9 @@ type builderOptions struct {
 	cfg          *elasticsearchv8.Config
 	v7Compatible bool
 	dialTimeout  time.Duration
-	scripts      map[string]string // id -> script map
+	scripts      map[string]string                // id -> script map
+	pipelines    map[string]*types.IngestPipeline // id -> ingest pipeline map
+	templates    *TemplateSet
 
 	caCert        []byte
 	username      string
@@ -52,6 +55,32 @@ func (es *ElasticsearchEx) registerScripts(ctx context.Context) error {
 	return nil
 }
 
+func (es *ElasticsearchEx) registerPipelines(ctx context.Context) error {
+	if len(es.options.pipelines) == 0 {
+		return nil
+	}
+
+	for id, pipeline := range es.options.pipelines {
+		_, err := es.PutPipeline(ctx, id, pipeline)
+		if err != nil {
+			return fmt.Errorf("failed to register pipeline %s: %w", id, err)
+		}
+	}
+	return nil
+}
+
+func (es *ElasticsearchEx) registerTemplates(ctx context.Context) error {
+	if es.options.templates == nil {
+		return nil
+	}
+
+	_, err := es.SyncTemplates(ctx, es.options.templates)
+	if err != nil {
+		return fmt.Errorf("failed to register templates: %w", err)
+	}
+	return nil
+}
+
 func New(opts ...Option) (*ElasticsearchEx, error) {
 	es := &ElasticsearchEx{}
 	for _, o := range opts {
@@ -103,11 +132,11 @@ func New(opts ...Option) (*ElasticsearchEx, error) {
 	}
 	// force es CACert to nil, it has bug handling cert in custom transport, we must hacking here
 	es.options.cfg.CACert = nil
-	typedClient, err := elasticsearchv8.NewTypedClient(*es.options.cfg)
+	es.TypedClient, err = elasticsearchv8.NewTypedClient(*es.options.cfg)
 	if err != nil {
 		return nil, err
 	}
-	client, err := elasticsearchv8.NewClient(*es.options.cfg)
+	es.Client, err = elasticsearchv8.NewClient(*es.options.cfg)
 	if err != nil {
 		return nil, err
 	}
@@ -117,10 +146,17 @@ func New(opts ...Option) (*ElasticsearchEx, error) {
 		return nil, err
 	}
 
-	return &ElasticsearchEx{
-		TypedClient: typedClient,
-		Client:      client,
-	}, nil
+	err = es.registerPipelines(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	err = es.registerTemplates(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	return es, nil
 }
 
 type Option func(*builderOptions)
@@ -167,6 +203,19 @@ func WithScripts(scriptMap map[string]string) Option {
 	}
 }
 
+func WithPipelines(pipelineMap map[string]*types.IngestPipeline) Option {
+	return func(o *builderOptions) {
+		o.pipelines = pipelineMap
+	}
+}
+
+// WithTemplates sync the ILM policies, the component and the index templates at startup, see SyncTemplates
+func WithTemplates(set *TemplateSet) Option {
+	return func(o *builderOptions) {
+		o.templates = set
+	}
+}
+
 func WithDialTimeout(timeout time.Duration) Option {
 	return func(o *builderOptions) {
 		o.dialTimeout = timeout
@@ -2,12 +2,28 @@ package elastic_wrapper
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 )
 
 var (
 	ErrNotFound = NewResponseStatusError(http.StatusNotFound)
 	ErrConflict = NewResponseStatusError(http.StatusConflict)
+
+	// ErrStaleVersion a versioned write is rejected because the document already has a same or newer version
+	ErrStaleVersion = errors.New("stale version, the document already has a same or newer version")
+
+	// ErrCreateVersioning create only supports internal versioning, index with WithExternalVersion instead
+	ErrCreateVersioning = errors.New("create does not support versioning, use index with an external version instead")
+
+	// ErrVersionWithoutType a write with WithVersion but no version type, es rejects internal versioning on writes
+	ErrVersionWithoutType = errors.New("versioned write requires a version type, use WithExternalVersion or WithVersionType")
+
+	// ErrBulkLoadActive the index is already in bulk load mode, by a running load or a crashed one
+	ErrBulkLoadActive = errors.New("bulk load mode is already active, call RestoreBulkLoadMode if a load crashed")
+
+	// ErrMissingTimestamp a document written into a data stream has no @timestamp
+	ErrMissingTimestamp = errors.New("missing @timestamp, required by data streams")
 )
 
 func NewResponseStatusError(code int) error {
@@ -29,3 +45,17 @@ func IsResponseStatusError(err error, code int) bool {
 	}
 	return errors.Is(err, ResponseStatusError(code))
 }
+
+// ErrStaleItems the versioned bulk writes rejected because the documents already have a same or newer version,
+// the other items are written. It matches ErrStaleVersion with errors.Is
+type ErrStaleItems struct {
+	IDs []string
+}
+
+func (e ErrStaleItems) Error() string {
+	return fmt.Sprintf("%d stale items skipped: %v", len(e.IDs), e.IDs)
+}
+
+func (e ErrStaleItems) Unwrap() error {
+	return ErrStaleVersion
+}
@@ -0,0 +1,279 @@
+package elastic_wrapper
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/ilm/movetostep"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+)
+
+const (
+	LifecyclePhaseHot    = "hot"
+	LifecyclePhaseWarm   = "warm"
+	LifecyclePhaseCold   = "cold"
+	LifecyclePhaseFrozen = "frozen"
+	LifecyclePhaseDelete = "delete"
+)
+
+// LifecyclePolicy an index lifecycle management policy, build it with NewLifecyclePolicy:
+//
+//	policy := NewLifecyclePolicy().
+//		Hot(ActionRollover(RolloverConditions{MaxAge: "1d", MaxPrimaryShardSize: "50gb"})).
+//		Warm("7d", ActionShrink(1), ActionForceMerge(1)).
+//		Delete("30d")
+//
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/ilm-index-lifecycle.html
+type LifecyclePolicy struct {
+	Phases LifecyclePhases `json:"phases"`
+	Meta   map[string]any  `json:"_meta,omitempty"`
+}
+
+type LifecyclePhases struct {
+	Hot    *LifecyclePhase `json:"hot,omitempty"`
+	Warm   *LifecyclePhase `json:"warm,omitempty"`
+	Cold   *LifecyclePhase `json:"cold,omitempty"`
+	Frozen *LifecyclePhase `json:"frozen,omitempty"`
+	Delete *LifecyclePhase `json:"delete,omitempty"`
+}
+
+// LifecyclePhase the actions of the phase, entered once the index is MinAge old,
+// the age counts from the rollover if the index is rolled over, else from its creation
+type LifecyclePhase struct {
+	MinAge  string         `json:"min_age,omitempty"`
+	Actions map[string]any `json:"actions"` // action name -> action config
+}
+
+// LifecycleAction one action of a LifecyclePhase, built by the Action* functions
+type LifecycleAction struct {
+	Name   string
+	Config any
+}
+
+// RolloverConditions roll over once any of the max conditions and all the min conditions are met,
+// sizes are byte size strings like 50gb and ages are durations like 7d
+type RolloverConditions struct {
+	MaxAge              string `json:"max_age,omitempty"`
+	MaxDocs             int64  `json:"max_docs,omitempty"`
+	MaxSize             string `json:"max_size,omitempty"`
+	MaxPrimaryShardSize string `json:"max_primary_shard_size,omitempty"`
+	MaxPrimaryShardDocs int64  `json:"max_primary_shard_docs,omitempty"`
+	MinAge              string `json:"min_age,omitempty"`
+	MinDocs             int64  `json:"min_docs,omitempty"`
+	MinSize             string `json:"min_size,omitempty"`
+	MinPrimaryShardSize string `json:"min_primary_shard_size,omitempty"`
+	MinPrimaryShardDocs int64  `json:"min_primary_shard_docs,omitempty"`
+}
+
+// ActionRollover only valid in the hot phase, the index must be written through an alias or a data stream
+func ActionRollover(conditions RolloverConditions) LifecycleAction {
+	return LifecycleAction{Name: "rollover", Config: conditions}
+}
+
+// ActionSetPriority the recovery priority of the index after a node restart, higher first
+func ActionSetPriority(priority int) LifecycleAction {
+	return LifecycleAction{Name: "set_priority", Config: map[string]int{"priority": priority}}
+}
+
+func ActionForceMerge(maxNumSegments int) LifecycleAction {
+	return LifecycleAction{Name: "forcemerge", Config: map[string]int{"max_num_segments": maxNumSegments}}
+}
+
+func ActionShrink(numberOfShards int) LifecycleAction {
+	return LifecycleAction{Name: "shrink", Config: map[string]int{"number_of_shards": numberOfShards}}
+}
+
+func ActionReadOnly() LifecycleAction {
+	return LifecycleAction{Name: "readonly", Config: map[string]any{}}
+}
+
+// ActionAllocate change the number of replicas of the index
+func ActionAllocate(numberOfReplicas int) LifecycleAction {
+	return LifecycleAction{Name: "allocate", Config: map[string]int{"number_of_replicas": numberOfReplicas}}
+}
+
+func ActionDelete() LifecycleAction {
+	return LifecycleAction{Name: "delete", Config: map[string]any{}}
+}
+
+// ActionCustom any other action, e.g. searchable_snapshot or migrate
+func ActionCustom(name string, config any) LifecycleAction {
+	return LifecycleAction{Name: name, Config: config}
+}
+
+func NewLifecyclePolicy() *LifecyclePolicy {
+	return &LifecyclePolicy{}
+}
+
+func newLifecyclePhase(minAge string, actions []LifecycleAction) *LifecyclePhase {
+	phase := &LifecyclePhase{MinAge: minAge, Actions: make(map[string]any, len(actions))}
+	for _, action := range actions {
+		phase.Actions[action.Name] = action.Config
+	}
+	return phase
+}
+
+// Hot the hot phase, entered right after the index is created
+func (p *LifecyclePolicy) Hot(actions ...LifecycleAction) *LifecyclePolicy {
+	p.Phases.Hot = newLifecyclePhase("", actions)
+	return p
+}
+
+func (p *LifecyclePolicy) Warm(minAge string, actions ...LifecycleAction) *LifecyclePolicy {
+	p.Phases.Warm = newLifecyclePhase(minAge, actions)
+	return p
+}
+
+func (p *LifecyclePolicy) Cold(minAge string, actions ...LifecycleAction) *LifecyclePolicy {
+	p.Phases.Cold = newLifecyclePhase(minAge, actions)
+	return p
+}
+
+func (p *LifecyclePolicy) Frozen(minAge string, actions ...LifecycleAction) *LifecyclePolicy {
+	p.Phases.Frozen = newLifecyclePhase(minAge, actions)
+	return p
+}
+
+// Delete delete the index once it is minAge old, the retention of the policy
+func (p *LifecyclePolicy) Delete(minAge string) *LifecyclePolicy {
+	p.Phases.Delete = newLifecyclePhase(minAge, []LifecycleAction{ActionDelete()})
+	return p
+}
+
+func (p *LifecyclePolicy) WithMeta(meta map[string]any) *LifecyclePolicy {
+	p.Meta = meta
+	return p
+}
+
+// PutLifecyclePolicy create or update the ILM policy, the indices using it pick up the new version
+// once they leave their current phase
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/ilm-put-lifecycle.html
+func (es *ElasticsearchEx) PutLifecyclePolicy(ctx context.Context, name string, policy *LifecyclePolicy) (*AcknowledgedResponse, error) {
+	body, err := json.Marshal(policy)
+	if err != nil {
+		return nil, err
+	}
+	return es.PutLifecyclePolicyRaw(ctx, name, body)
+}
+
+// PutLifecyclePolicyRaw create or update the ILM policy by the JSON definition of the policy, without the `policy` wrapper
+func (es *ElasticsearchEx) PutLifecyclePolicyRaw(ctx context.Context, name string, policy []byte) (*AcknowledgedResponse, error) {
+	body, err := json.Marshal(map[string]json.RawMessage{"policy": policy})
+	if err != nil {
+		return nil, err
+	}
+	var rsp AcknowledgedResponse
+	err = doGetResponse[ErrGeneric](ctx, es.Ilm.PutLifecycle(name).Raw(body), &rsp)
+	return &rsp, err
+}
+
+type LifecyclePolicyInfo struct {
+	Version      int64           `json:"version"`
+	ModifiedDate string          `json:"modified_date"`
+	Policy       LifecyclePolicy `json:"policy"`
+	InUseBy      struct {
+		Indices     []string `json:"indices"`
+		DataStreams []string `json:"data_streams"`
+	} `json:"in_use_by"`
+}
+
+// GetLifecyclePoliciesResponse policy name -> policy
+type GetLifecyclePoliciesResponse map[string]LifecyclePolicyInfo
+
+func (g *GetLifecyclePoliciesResponse) FromJSON(bytes []byte) error {
+	return FromJSONImplDefault(bytes, g)
+}
+
+// GetLifecyclePolicies get the ILM policies by name, all policies if no name given.
+// ErrNotFound if any of the policies does not exist
+func (es *ElasticsearchEx) GetLifecyclePolicies(ctx context.Context, names ...string) (GetLifecyclePoliciesResponse, error) {
+	req := es.Ilm.GetLifecycle()
+	if len(names) > 0 {
+		req.Policy(strings.Join(names, ","))
+	}
+	var rsp GetLifecyclePoliciesResponse
+	err := doGetResponse[ErrGeneric](ctx, req, &rsp)
+	return rsp, err
+}
+
+// DeleteLifecyclePolicy ErrNotFound if not exists, it fails if the policy is in use
+func (es *ElasticsearchEx) DeleteLifecyclePolicy(ctx context.Context, name string) (*AcknowledgedResponse, error) {
+	var rsp AcknowledgedResponse
+	err := doGetResponse[ErrGeneric](ctx, es.Ilm.DeleteLifecycle(name), &rsp)
+	return &rsp, err
+}
+
+// LifecycleExplain the lifecycle state of an index
+type LifecycleExplain struct {
+	Index                string         `json:"index"`
+	Managed              bool           `json:"managed"`
+	Policy               string         `json:"policy"`
+	Phase                string         `json:"phase"`
+	Action               string         `json:"action"`
+	Step                 string         `json:"step"`
+	Age                  string         `json:"age"`
+	FailedStep           string         `json:"failed_step"`
+	FailedStepRetryCount int            `json:"failed_step_retry_count"`
+	IsAutoRetryableError bool           `json:"is_auto_retryable_error"`
+	StepInfo             map[string]any `json:"step_info"` // the error of the failed step, or the progress of the step
+	LifecycleDateMillis  int64          `json:"lifecycle_date_millis"`
+	PhaseTimeMillis      int64          `json:"phase_time_millis"`
+	StepTimeMillis       int64          `json:"step_time_millis"`
+}
+
+// Failed the index is stuck in the ERROR step, see RetryLifecycle
+func (l *LifecycleExplain) Failed() bool {
+	return l.Step == "ERROR"
+}
+
+type ExplainLifecycleResponse struct {
+	Indices map[string]LifecycleExplain `json:"indices"`
+}
+
+func (e *ExplainLifecycleResponse) FromJSON(bytes []byte) error {
+	return FromJSONImplDefault(bytes, e)
+}
+
+// ExplainLifecycle the current lifecycle phase, action and step of the indices, wildcards are supported
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/ilm-explain-lifecycle.html
+func (es *ElasticsearchEx) ExplainLifecycle(ctx context.Context, index string) (map[string]LifecycleExplain, error) {
+	var rsp ExplainLifecycleResponse
+	err := doGetResponse[ErrGeneric](ctx, es.Ilm.ExplainLifecycle(index), &rsp)
+	return rsp.Indices, err
+}
+
+// MoveLifecycleToStep manually move the index from the current step to the next step, current must match
+// the current step of the index. Name of next can be empty to move to the first step of the action
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/ilm-move-to-step.html
+func (es *ElasticsearchEx) MoveLifecycleToStep(ctx context.Context, index string, current, next *types.StepKey) (*AcknowledgedResponse, error) {
+	request := movetostep.NewRequest()
+	request.CurrentStep = current
+	request.NextStep = next
+	var rsp AcknowledgedResponse
+	err := doGetResponse[ErrGeneric](ctx, es.Ilm.MoveToStep(index).Request(request), &rsp)
+	return &rsp, err
+}
+
+// RetryLifecycle retry the failed step of the indices which are in the ERROR step
+func (es *ElasticsearchEx) RetryLifecycle(ctx context.Context, index string) (*AcknowledgedResponse, error) {
+	var rsp AcknowledgedResponse
+	err := doGetResponse[ErrGeneric](ctx, es.Ilm.Retry(index), &rsp)
+	return &rsp, err
+}
+
+type RemoveLifecyclePolicyResponse struct {
+	HasFailures   bool     `json:"has_failures"`
+	FailedIndexes []string `json:"failed_indexes"`
+}
+
+func (r *RemoveLifecyclePolicyResponse) FromJSON(bytes []byte) error {
+	return FromJSONImplDefault(bytes, r)
+}
+
+// RemoveLifecyclePolicy stop managing the indices by ILM, wildcards are supported
+func (es *ElasticsearchEx) RemoveLifecyclePolicy(ctx context.Context, index string) (*RemoveLifecyclePolicyResponse, error) {
+	var rsp RemoveLifecyclePolicyResponse
+	err := doGetResponse[ErrGeneric](ctx, es.Ilm.RemovePolicy(index), &rsp)
+	return &rsp, err
+}
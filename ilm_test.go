@@ -0,0 +1,96 @@
+package elastic_wrapper
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestLifecyclePolicyJSON(t *testing.T) {
+	policy := NewLifecyclePolicy().
+		Hot(ActionRollover(RolloverConditions{MaxAge: "1d", MaxPrimaryShardSize: "50gb"}), ActionSetPriority(100)).
+		Warm("7d", ActionShrink(1), ActionForceMerge(1)).
+		Delete("30d")
+
+	got, err := json.Marshal(policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := `{"phases":{` +
+		`"hot":{"actions":{"rollover":{"max_age":"1d","max_primary_shard_size":"50gb"},"set_priority":{"priority":100}}},` +
+		`"warm":{"min_age":"7d","actions":{"forcemerge":{"max_num_segments":1},"shrink":{"number_of_shards":1}}},` +
+		`"delete":{"min_age":"30d","actions":{"delete":{}}}}}`
+	if string(got) != expect {
+		t.Errorf("expect %s, got %s", expect, got)
+	}
+}
+
+func TestLifecyclePolicy(t *testing.T) {
+	es := newClient(t)
+
+	policyName := "test_logs_retention"
+	demoIndex := "test_ilm_index"
+
+	t.Cleanup(func() {
+		deleted, err := es.IndexDelete(context.Background(), demoIndex)
+		if err != nil {
+			t.Fatalf("delete index failed, err=%v", err)
+		}
+		if !deleted {
+			t.Fatalf("delete index failed, deleted=%v", deleted)
+		}
+		if _, err := es.DeleteLifecyclePolicy(context.Background(), policyName); err != nil {
+			t.Fatalf("delete lifecycle policy failed, err=%v", err)
+		}
+	})
+
+	set := &TemplateSet{LifecyclePolicies: map[string]any{
+		policyName: NewLifecyclePolicy().
+			Hot(ActionSetPriority(100)).
+			Warm("7d", ActionForceMerge(1)).
+			Delete("30d"),
+	}}
+	result, err := es.SyncTemplates(context.Background(), set)
+	if err != nil {
+		t.Fatalf("sync lifecycle policy failed, err=%v", err)
+	}
+	t.Logf("result=%+v", result)
+	result, err = es.SyncTemplates(context.Background(), set)
+	if err != nil {
+		t.Fatalf("sync lifecycle policy failed, err=%v", err)
+	}
+	if len(result.Unchanged) != 1 {
+		t.Fatalf("expect the policy unchanged, result=%+v", result)
+	}
+
+	policies, err := es.GetLifecyclePolicies(context.Background(), policyName)
+	if err != nil {
+		t.Fatalf("get lifecycle policies failed, err=%v", err)
+	}
+	if policies[policyName].Policy.Phases.Delete == nil {
+		t.Fatalf("expect the delete phase, policy=%+v", policies[policyName])
+	}
+
+	if _, err := es.IndexCreateSimple(context.Background(), demoIndex, nil); err != nil {
+		t.Fatalf("create simple index failed, err=%v", err)
+	}
+	if _, err := es.IndexPutSettingsRaw(context.Background(), demoIndex, map[string]any{"index.lifecycle.name": policyName}); err != nil {
+		t.Fatalf("set lifecycle policy failed, err=%v", err)
+	}
+	explain, err := es.ExplainLifecycle(context.Background(), demoIndex)
+	if err != nil {
+		t.Fatalf("explain lifecycle failed, err=%v", err)
+	}
+	t.Logf("explain=%+v", explain[demoIndex])
+	if !explain[demoIndex].Managed || explain[demoIndex].Policy != policyName {
+		t.Fatalf("expect the index managed by %s, explain=%+v", policyName, explain[demoIndex])
+	}
+
+	removed, err := es.RemoveLifecyclePolicy(context.Background(), demoIndex)
+	if err != nil {
+		t.Fatalf("remove lifecycle policy failed, err=%v", err)
+	}
+	if removed.HasFailures {
+		t.Fatalf("remove lifecycle policy failed, rsp=%+v", removed)
+	}
+}
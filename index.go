@@ -60,6 +60,15 @@ func (es *ElasticsearchEx) IndexCreateSimple(ctx context.Context, indexName stri
 	return es.IndexCreate(ctx, indexName, req)
 }
 
+// IndexCreateFor create the index with the mapping generated from the struct tags of T, see MappingFor
+func IndexCreateFor[T any](ctx context.Context, es *ElasticsearchEx, indexName string, opts ...MappingOption) (*IndexCreateResponse, error) {
+	mappings, err := MappingFor[T](opts...)
+	if err != nil {
+		return nil, err
+	}
+	return es.IndexCreateSimple(ctx, indexName, mappings)
+}
+
 func (es *ElasticsearchEx) IndexCreateRaw(ctx context.Context, indexName string, indexCreateReq []byte) (*IndexCreateResponse, error) {
 	var rsp IndexCreateResponse
 	req := es.Indices.Create(indexName).
@@ -79,38 +88,62 @@ func (es *ElasticsearchEx) IndexExists(ctx context.Context, indexName string) (b
 	return es.Indices.Exists(indexName).IsSuccess(ctx)
 }
 
+// IndexInfo the aliases, mappings and settings of an index
 type IndexInfo struct {
-	Aliases  map[string]any `json:"aliases"`
-	Mappings map[string]any `json:"mappings"`
-	Settings map[string]any `json:"settings"`
+	Aliases    map[string]types.AliasDefinition
+	Mappings   *types.TypeMapping
+	Settings   *IndexSettingsInfo
+	DataStream string // the data stream of a backing index
+}
+
+type indexInfoJSON struct {
+	Aliases    map[string]types.AliasDefinition `json:"aliases"`
+	Mappings   map[string]any                   `json:"mappings"`
+	Settings   map[string]any                   `json:"settings"`
+	Defaults   map[string]any                   `json:"defaults"`
+	DataStream string                           `json:"data_stream"`
 }
 
+// IndexInfoResponse index name -> index info
 type IndexInfoResponse map[string]IndexInfo
 
 func (i *IndexInfoResponse) FromJSON(bytes []byte) error {
-	return json.Unmarshal(bytes, i)
+	var raw map[string]indexInfoJSON
+	if err := json.Unmarshal(bytes, &raw); err != nil {
+		return err
+	}
+	*i = make(IndexInfoResponse, len(raw))
+	for name, info := range raw {
+		mappings, err := ParseTypeMapping(info.Mappings)
+		if err != nil {
+			return fmt.Errorf("index %s: %w", name, err)
+		}
+		settings, err := parseIndexSettings(info.Settings, info.Defaults)
+		if err != nil {
+			return fmt.Errorf("index %s: %w", name, err)
+		}
+		(*i)[name] = IndexInfo{
+			Aliases:    info.Aliases,
+			Mappings:   mappings,
+			Settings:   settings,
+			DataStream: info.DataStream,
+		}
+	}
+	return nil
 }
 
-func (es *ElasticsearchEx) IndexGet(ctx context.Context, indexName string) (*IndexInfoResponse, error) {
+// IndexGet the aliases, the typed mappings and settings of the indices, wildcards are supported.
+// Supported options: WithFlatSettings, WithIncludeDefaults
+func (es *ElasticsearchEx) IndexGet(ctx context.Context, indexName string, opts ...RequestOption) (*IndexInfoResponse, error) {
+	o := newRequestOptions(opts)
 	req := es.Indices.Get(indexName)
+	if o.flatSettings {
+		req.FlatSettings(true)
+	}
+	if o.includeDefaults {
+		req.IncludeDefaults(true)
+	}
 	var rsp IndexInfoResponse
 	err := doGetResponse[ErrGeneric](ctx, req, &rsp)
 	return &rsp, err
 }
-
-type IndexStats struct {
-	Shards  map[string]any `json:"_shards"`
-	All     map[string]any `json:"_all"`
-	Indices map[string]any `json:"indices"`
-}
-
-func (i *IndexStats) FromJSON(bytes []byte) error {
-	return json.Unmarshal(bytes, i)
-}
-
-func (es *ElasticsearchEx) IndexStats(ctx context.Context, indexName string) (*IndexStats, error) {
-	req := es.Indices.Stats().Index(indexName)
-	var rsp IndexStats
-	err := doGetResponse[ErrGeneric](ctx, req, &rsp)
-	return &rsp, err
-}
@@ -0,0 +1,378 @@
+package elastic_wrapper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/healthstatus"
+)
+
+// IndexBlock the block of IndexAddBlock
+type IndexBlock string
+
+const (
+	IndexBlockWrite    IndexBlock = "write"     // no writes, metadata changes are allowed
+	IndexBlockReadOnly IndexBlock = "read_only" // no writes nor metadata changes
+	IndexBlockRead     IndexBlock = "read"      // no reads
+	IndexBlockMetadata IndexBlock = "metadata"  // no metadata reads nor changes, e.g. get mapping
+)
+
+// the time to wait for the shards to be relocated and the index to be green before a shrink
+const defaultResizeWaitTimeout = "5m"
+
+// ErrIndexOperationFailed the error response of an index admin operation, e.g. index_closed_exception
+type ErrIndexOperationFailed struct {
+	TheError struct {
+		RootCause []struct {
+			Type   string `json:"type"`
+			Reason string `json:"reason"`
+			Index  string `json:"index"`
+		} `json:"root_cause"`
+		Type   string `json:"type"`
+		Reason string `json:"reason"`
+		Index  string `json:"index"`
+	} `json:"error"`
+	Status int `json:"status"`
+}
+
+func (e ErrIndexOperationFailed) Error() string {
+	return fmt.Sprintf("index operation failed, type: %s, reason: %s, code: %v", e.TheError.Type, e.TheError.Reason, e.Status)
+}
+
+// ErrInvalidResize the number of shards of the target is not possible for the resize of the index
+type ErrInvalidResize struct {
+	Index        string
+	SourceShards int
+	TargetShards int
+	Reason       string
+}
+
+func (e ErrInvalidResize) Error() string {
+	return fmt.Sprintf("cannot resize %s from %d to %d shards: %s", e.Index, e.SourceShards, e.TargetShards, e.Reason)
+}
+
+type IndexOpenResponse struct {
+	Acknowledged       bool `json:"acknowledged"`
+	ShardsAcknowledged bool `json:"shards_acknowledged"`
+}
+
+func (i *IndexOpenResponse) FromJSON(bytes []byte) error {
+	return FromJSONImplDefault(bytes, i)
+}
+
+// IndexOpen open the closed indices, wildcards are supported
+func (es *ElasticsearchEx) IndexOpen(ctx context.Context, index string) (*IndexOpenResponse, error) {
+	var rsp IndexOpenResponse
+	err := doGetResponse[ErrIndexOperationFailed](ctx, es.Indices.Open(index), &rsp)
+	return &rsp, err
+}
+
+type IndexCloseResponse struct {
+	Acknowledged       bool `json:"acknowledged"`
+	ShardsAcknowledged bool `json:"shards_acknowledged"`
+	Indices            map[string]struct {
+		Closed bool `json:"closed"`
+	} `json:"indices"`
+}
+
+func (i *IndexCloseResponse) FromJSON(bytes []byte) error {
+	return FromJSONImplDefault(bytes, i)
+}
+
+// IndexClose close the indices, wildcards are supported. A closed index is neither readable nor writable,
+// but its static settings can be changed
+func (es *ElasticsearchEx) IndexClose(ctx context.Context, index string) (*IndexCloseResponse, error) {
+	var rsp IndexCloseResponse
+	err := doGetResponse[ErrIndexOperationFailed](ctx, es.Indices.Close(index), &rsp)
+	return &rsp, err
+}
+
+type IndexAddBlockResponse struct {
+	Acknowledged       bool `json:"acknowledged"`
+	ShardsAcknowledged bool `json:"shards_acknowledged"`
+	Indices            []struct {
+		Name    string `json:"name"`
+		Blocked bool   `json:"blocked"`
+	} `json:"indices"`
+}
+
+func (i *IndexAddBlockResponse) FromJSON(bytes []byte) error {
+	return FromJSONImplDefault(bytes, i)
+}
+
+// IndexAddBlock add the block to the indices, wildcards are supported
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/index-modules-blocks.html
+func (es *ElasticsearchEx) IndexAddBlock(ctx context.Context, index string, block IndexBlock) (*IndexAddBlockResponse, error) {
+	var rsp IndexAddBlockResponse
+	err := doGetResponse[ErrIndexOperationFailed](ctx, es.Indices.AddBlock(index, string(block)), &rsp)
+	return &rsp, err
+}
+
+// IndexRemoveBlock remove the block from the indices, there is no remove block API, the block setting is reset
+func (es *ElasticsearchEx) IndexRemoveBlock(ctx context.Context, index string, block IndexBlock) (*AcknowledgedResponse, error) {
+	return es.IndexPutSettingsRaw(ctx, index, map[string]any{"index.blocks." + string(block): nil})
+}
+
+// IndexResizeOptions the optional settings of IndexShrink, IndexSplit and IndexClone
+type IndexResizeOptions struct {
+	Settings            map[string]any // the settings of the target index by flat keys, on top of the source settings
+	Aliases             map[string]types.Alias
+	WaitForActiveShards string // the active shards of the target to wait for, 1 by default, "all" for green
+}
+
+type IndexResizeResponse struct {
+	Acknowledged       bool   `json:"acknowledged"`
+	ShardsAcknowledged bool   `json:"shards_acknowledged"`
+	Index              string `json:"index"`
+}
+
+func (i *IndexResizeResponse) FromJSON(bytes []byte) error {
+	return FromJSONImplDefault(bytes, i)
+}
+
+// resize requests share the same body and parameters
+type resizeRequest[R any] interface {
+	HttpRequest
+	Raw(raw json.RawMessage) R
+	WaitForActiveShards(value string) R
+}
+
+// doResize the target gets the settings of the source, including the block and allocation settings the resize
+// prerequisites add to the source, so reset gives the values of the target for them
+func doResize[R resizeRequest[R]](ctx context.Context, req R, numberOfShards int, reset map[string]any, opts *IndexResizeOptions) (*IndexResizeResponse, error) {
+	if opts == nil {
+		opts = &IndexResizeOptions{}
+	}
+	settings := make(map[string]any, len(reset)+len(opts.Settings)+1)
+	for k, v := range reset {
+		settings[k] = v
+	}
+	if numberOfShards > 0 {
+		settings["index.number_of_shards"] = numberOfShards
+	}
+	for k, v := range opts.Settings {
+		settings[k] = v
+	}
+	resize := map[string]any{"settings": settings}
+	// aliases is an object, null is rejected
+	if len(opts.Aliases) > 0 {
+		resize["aliases"] = opts.Aliases
+	}
+	body, err := json.Marshal(resize)
+	if err != nil {
+		return nil, err
+	}
+	req.Raw(body)
+	if opts.WaitForActiveShards != "" {
+		req.WaitForActiveShards(opts.WaitForActiveShards)
+	}
+	var rsp IndexResizeResponse
+	err = doGetResponse[ErrIndexOperationFailed](ctx, req, &rsp)
+	return &rsp, err
+}
+
+// the settings IndexShrink changes on the source, a copy of every shard must be on a single node,
+// so there must be no replicas, they could not be allocated next to their primaries
+var shrinkSettingKeys = []string{
+	"index.routing.allocation.require._name",
+	"index.blocks.write",
+	"index.number_of_replicas",
+	"index.auto_expand_replicas",
+}
+
+// IndexShrink shrink the index into the new target index with fewer primary shards, numberOfShards must be a factor
+// of the number of shards of the index. The prerequisites are taken care of:
+//
+//  1. drop the replicas, and relocate every shard to the node which already has the most primaries of the index
+//  2. block writes on the index
+//  3. wait for the relocation to complete and the index to be green
+//  4. shrink, then restore the previous replicas, write block and allocation requirement of the index
+//
+// The index is left as it was if any step fails, the target gets the replicas of the index
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/indices-shrink-index.html
+func (es *ElasticsearchEx) IndexShrink(ctx context.Context, index, target string, numberOfShards int, opts *IndexResizeOptions) (rsp *IndexResizeResponse, err error) {
+	shards, err := es.indexShards(ctx, index)
+	if err != nil {
+		return nil, err
+	}
+	if numberOfShards <= 0 || numberOfShards >= shards.primaries || shards.primaries%numberOfShards != 0 {
+		return nil, ErrInvalidResize{Index: index, SourceShards: shards.primaries, TargetShards: numberOfShards,
+			Reason: "the target number of shards must be a factor of the source number of shards"}
+	}
+
+	current, err := es.indexFlatSettings(ctx, index)
+	if err != nil {
+		return nil, err
+	}
+	// a setting which is not set is restored by null
+	previous := make(map[string]any, len(shrinkSettingKeys))
+	for _, key := range shrinkSettingKeys {
+		previous[key] = current[key]
+	}
+
+	prerequisites := map[string]any{
+		"index.routing.allocation.require._name": shards.busiestNode,
+		"index.blocks.write":                     true,
+		"index.number_of_replicas":               0,
+		"index.auto_expand_replicas":             "false",
+	}
+	if _, err := es.IndexPutSettingsRaw(ctx, index, prerequisites); err != nil {
+		return nil, fmt.Errorf("prepare %s for shrink failed: %w", index, err)
+	}
+	defer func() {
+		// the source is restored whether the shrink succeeded or not
+		if _, restoreErr := es.IndexPutSettingsRaw(context.Background(), index, previous); restoreErr != nil {
+			err = errors.Join(err, fmt.Errorf("restore %s after shrink failed: %w", index, restoreErr))
+		}
+	}()
+
+	if err := es.waitIndexGreen(ctx, index); err != nil {
+		return nil, fmt.Errorf("relocate %s for shrink failed: %w", index, err)
+	}
+	reset := map[string]any{
+		"index.routing.allocation.require._name": nil,
+		"index.blocks.write":                     nil,
+		"index.number_of_replicas":               previous["index.number_of_replicas"],
+		"index.auto_expand_replicas":             previous["index.auto_expand_replicas"],
+	}
+	return doResize(ctx, es.Indices.Shrink(index, target), numberOfShards, reset, opts)
+}
+
+// IndexSplit split the index into the new target index with more primary shards, numberOfShards must be a multiple
+// of the number of shards of the index. Writes on the index are blocked during the split
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/indices-split-index.html
+func (es *ElasticsearchEx) IndexSplit(ctx context.Context, index, target string, numberOfShards int, opts *IndexResizeOptions) (*IndexResizeResponse, error) {
+	shards, err := es.indexShards(ctx, index)
+	if err != nil {
+		return nil, err
+	}
+	if numberOfShards <= shards.primaries || numberOfShards%shards.primaries != 0 {
+		return nil, ErrInvalidResize{Index: index, SourceShards: shards.primaries, TargetShards: numberOfShards,
+			Reason: "the target number of shards must be a multiple of the source number of shards"}
+	}
+	var rsp *IndexResizeResponse
+	err = es.withWriteBlock(ctx, index, func() (err error) {
+		rsp, err = doResize(ctx, es.Indices.Split(index, target), numberOfShards, writeBlockReset, opts)
+		return err
+	})
+	return rsp, err
+}
+
+// IndexClone copy the index into the new target index with the same number of shards,
+// writes on the index are blocked during the clone
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/indices-clone-index.html
+func (es *ElasticsearchEx) IndexClone(ctx context.Context, index, target string, opts *IndexResizeOptions) (*IndexResizeResponse, error) {
+	var rsp *IndexResizeResponse
+	err := es.withWriteBlock(ctx, index, func() (err error) {
+		rsp, err = doResize(ctx, es.Indices.Clone(index, target), 0, writeBlockReset, opts)
+		return err
+	})
+	return rsp, err
+}
+
+// the target of split and clone is writable, whether the write block was added by withWriteBlock or not
+var writeBlockReset = map[string]any{"index.blocks.write": nil}
+
+// withWriteBlock run fn with writes blocked on the index, the block is only lifted if it was added here
+func (es *ElasticsearchEx) withWriteBlock(ctx context.Context, index string, fn func() error) (err error) {
+	settings, err := es.indexFlatSettings(ctx, index)
+	if err != nil {
+		return err
+	}
+	if blocked, _ := strconv.ParseBool(fmt.Sprint(settings["index.blocks.write"])); blocked {
+		return fn()
+	}
+	if _, err := es.IndexAddBlock(ctx, index, IndexBlockWrite); err != nil {
+		return fmt.Errorf("block writes of %s failed: %w", index, err)
+	}
+	defer func() {
+		if _, removeErr := es.IndexRemoveBlock(context.Background(), index, IndexBlockWrite); removeErr != nil {
+			err = errors.Join(err, fmt.Errorf("lift the write block of %s failed: %w", index, removeErr))
+		}
+	}()
+	return fn()
+}
+
+type searchShardsResponse struct {
+	Nodes map[string]struct {
+		Name string `json:"name"`
+	} `json:"nodes"`
+	Shards [][]struct {
+		Index   string `json:"index"`
+		Shard   int    `json:"shard"`
+		Primary bool   `json:"primary"`
+		State   string `json:"state"`
+		Node    string `json:"node"`
+	} `json:"shards"`
+}
+
+func (s *searchShardsResponse) FromJSON(bytes []byte) error {
+	return FromJSONImplDefault(bytes, s)
+}
+
+type indexShardsInfo struct {
+	primaries   int
+	busiestNode string // the name of the node with the most primaries of the index
+}
+
+// indexShards the number of primaries of the single index and the node holding most of them
+func (es *ElasticsearchEx) indexShards(ctx context.Context, index string) (*indexShardsInfo, error) {
+	var rsp searchShardsResponse
+	if err := doGetResponse[ErrIndexOperationFailed](ctx, es.SearchShards().Index(index), &rsp); err != nil {
+		return nil, err
+	}
+	info := &indexShardsInfo{}
+	perNode := make(map[string]int)
+	for _, copies := range rsp.Shards {
+		for _, shard := range copies {
+			if shard.Index != index {
+				return nil, fmt.Errorf("%s must be a single concrete index, got shards of %s", index, shard.Index)
+			}
+			if shard.Primary {
+				info.primaries++
+				perNode[shard.Node]++
+			}
+		}
+	}
+	busiest := -1
+	for node, n := range perNode {
+		if n > busiest || (n == busiest && rsp.Nodes[node].Name < info.busiestNode) {
+			busiest, info.busiestNode = n, rsp.Nodes[node].Name
+		}
+	}
+	if info.primaries == 0 {
+		return nil, fmt.Errorf("no started primary shard of %s", index)
+	}
+	return info, nil
+}
+
+type clusterHealthResponse struct {
+	Status           string `json:"status"`
+	TimedOut         bool   `json:"timed_out"`
+	RelocatingShards int    `json:"relocating_shards"`
+}
+
+func (c *clusterHealthResponse) FromJSON(bytes []byte) error {
+	return FromJSONImplDefault(bytes, c)
+}
+
+// waitIndexGreen wait for the index to be green without relocating shards
+func (es *ElasticsearchEx) waitIndexGreen(ctx context.Context, index string) error {
+	req := es.Cluster.Health().
+		Index(index).
+		WaitForStatus(healthstatus.Green).
+		WaitForNoRelocatingShards(true).
+		Timeout(defaultResizeWaitTimeout)
+	var rsp clusterHealthResponse
+	if err := doGetResponse[ErrGeneric](ctx, req, &rsp); err != nil {
+		return err
+	}
+	if rsp.TimedOut {
+		return fmt.Errorf("index %s is %s with %d relocating shards after %s", index, rsp.Status, rsp.RelocatingShards, defaultResizeWaitTimeout)
+	}
+	return nil
+}
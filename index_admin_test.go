@@ -0,0 +1,127 @@
+package elastic_wrapper
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/indices/create"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+)
+
+func TestIndexAdmin(t *testing.T) {
+	es := newClient(t)
+	ctx := context.Background()
+
+	demoIndex := "test_index_admin"
+	shrunkIndex := "test_index_admin_shrunk"
+	splitIndex := "test_index_admin_split"
+	clonedIndex := "test_index_admin_cloned"
+
+	t.Cleanup(func() {
+		for _, index := range []string{demoIndex, shrunkIndex, splitIndex, clonedIndex} {
+			deleted, err := es.IndexDelete(context.Background(), index)
+			if err != nil {
+				t.Fatalf("delete index failed, err=%v", err)
+			}
+			if !deleted {
+				t.Fatalf("delete index failed, deleted=%v", deleted)
+			}
+		}
+	})
+
+	// the replicas are dropped during the shrink, so the index is green even on a single node cluster
+	req := create.NewRequest()
+	req.Settings = &types.IndexSettings{NumberOfShards: "2", NumberOfReplicas: "1"}
+	if _, err := es.IndexCreate(ctx, demoIndex, req); err != nil {
+		t.Fatalf("create index failed, err=%v", err)
+	}
+
+	closed, err := es.IndexClose(ctx, demoIndex)
+	if err != nil {
+		t.Fatalf("close index failed, err=%v", err)
+	}
+	if !closed.Indices[demoIndex].Closed {
+		t.Fatalf("expect the index closed, rsp=%+v", closed)
+	}
+	if _, err := es.IndexOpen(ctx, demoIndex); err != nil {
+		t.Fatalf("open index failed, err=%v", err)
+	}
+
+	var errResize ErrInvalidResize
+	if _, err := es.IndexShrink(ctx, demoIndex, shrunkIndex, 3, nil); !errors.As(err, &errResize) {
+		t.Fatalf("expect ErrInvalidResize, err=%v", err)
+	}
+	if _, err := es.IndexSplit(ctx, demoIndex, splitIndex, 3, nil); !errors.As(err, &errResize) {
+		t.Fatalf("expect ErrInvalidResize, err=%v", err)
+	}
+
+	shrunk, err := es.IndexShrink(ctx, demoIndex, shrunkIndex, 1, nil)
+	if err != nil {
+		t.Fatalf("shrink index failed, err=%v", err)
+	}
+	t.Logf("shrunk=%+v", shrunk)
+	split, err := es.IndexSplit(ctx, demoIndex, splitIndex, 4, nil)
+	if err != nil {
+		t.Fatalf("split index failed, err=%v", err)
+	}
+	t.Logf("split=%+v", split)
+	if _, err := es.IndexClone(ctx, demoIndex, clonedIndex, nil); err != nil {
+		t.Fatalf("clone index failed, err=%v", err)
+	}
+
+	for index, expect := range map[string]string{shrunkIndex: "1", splitIndex: "4", clonedIndex: "2"} {
+		settings, err := es.indexFlatSettings(ctx, index)
+		if err != nil {
+			t.Fatalf("get settings failed, err=%v", err)
+		}
+		if settings["index.number_of_shards"] != expect || settings["index.blocks.write"] != nil {
+			t.Fatalf("expect %s with %s shards and no write block, settings=%v", index, expect, settings)
+		}
+	}
+
+	// the source is writable again
+	settings, err := es.indexFlatSettings(ctx, demoIndex)
+	if err != nil {
+		t.Fatalf("get settings failed, err=%v", err)
+	}
+	if settings["index.blocks.write"] != nil || settings["index.routing.allocation.require._name"] != nil ||
+		settings["index.number_of_replicas"] != "1" {
+		t.Fatalf("expect the resize prerequisites lifted, settings=%v", settings)
+	}
+
+	// a write block the index already has is kept
+	if _, err := es.IndexAddBlock(ctx, demoIndex, IndexBlockWrite); err != nil {
+		t.Fatalf("add block failed, err=%v", err)
+	}
+	if _, err := es.IndexShrink(ctx, demoIndex, shrunkIndex+"_blocked", 1, nil); err != nil {
+		t.Fatalf("shrink index failed, err=%v", err)
+	}
+	if deleted, err := es.IndexDelete(ctx, shrunkIndex+"_blocked"); err != nil || !deleted {
+		t.Fatalf("delete index failed, deleted=%v err=%v", deleted, err)
+	}
+	if settings, err = es.indexFlatSettings(ctx, demoIndex); err != nil {
+		t.Fatalf("get settings failed, err=%v", err)
+	}
+	if settings["index.blocks.write"] != "true" {
+		t.Fatalf("expect the write block kept, settings=%v", settings)
+	}
+	if _, err := es.IndexRemoveBlock(ctx, demoIndex, IndexBlockWrite); err != nil {
+		t.Fatalf("remove block failed, err=%v", err)
+	}
+
+	blocked, err := es.IndexAddBlock(ctx, demoIndex, IndexBlockReadOnly)
+	if err != nil {
+		t.Fatalf("add block failed, err=%v", err)
+	}
+	if len(blocked.Indices) != 1 || !blocked.Indices[0].Blocked {
+		t.Fatalf("expect the index blocked, rsp=%+v", blocked)
+	}
+	if _, err := es.IndexRemoveBlock(ctx, demoIndex, IndexBlockReadOnly); err != nil {
+		t.Fatalf("remove block failed, err=%v", err)
+	}
+
+	if _, err := es.IndexOpen(ctx, "test_index_admin_missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expect the open of a missing index to fail, err=%v", err)
+	}
+}
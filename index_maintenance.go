@@ -0,0 +1,127 @@
+package elastic_wrapper
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// ShardFailure the failure of a single shard of a broadcast operation, e.g. refresh
+type ShardFailure struct {
+	Index  string      `json:"index"`
+	Shard  int         `json:"shard"`
+	Node   string      `json:"node,omitempty"`
+	Status string      `json:"status,omitempty"`
+	Reason *ErrorCause `json:"reason,omitempty"`
+}
+
+// ShardsSummary the shards a broadcast operation ran on
+type ShardsSummary struct {
+	Total      int            `json:"total"`
+	Successful int            `json:"successful"`
+	Failed     int            `json:"failed"`
+	Failures   []ShardFailure `json:"failures,omitempty"`
+}
+
+// ShardsResponse the response of refresh, flush and force merge
+type ShardsResponse struct {
+	Shards ShardsSummary `json:"_shards"`
+}
+
+func (s *ShardsResponse) FromJSON(bytes []byte) error {
+	return FromJSONImplDefault(bytes, s)
+}
+
+// ErrShardFailures the operation succeeded on some shards only
+type ErrShardFailures struct {
+	Operation string
+	Shards    ShardsSummary
+}
+
+func (e ErrShardFailures) Error() string {
+	msg := fmt.Sprintf("%s failed on %d of %d shards", e.Operation, e.Shards.Failed, e.Shards.Total)
+	if len(e.Shards.Failures) > 0 {
+		first := e.Shards.Failures[0]
+		cause := first.Reason
+		if cause == nil {
+			cause = &ErrorCause{}
+		}
+		msg += fmt.Sprintf(", first failure: index=%s shard=%d type=%s reason=%s", first.Index, first.Shard, cause.Type, cause.Reason)
+	}
+	return msg
+}
+
+// checkShards ErrShardFailures if the operation failed on any shard
+func checkShards(operation string, rsp *ShardsResponse) error {
+	if rsp.Shards.Failed > 0 {
+		return ErrShardFailures{Operation: operation, Shards: rsp.Shards}
+	}
+	return nil
+}
+
+// IndexRefresh make the recent writes of the indices searchable, wildcards are supported
+func (es *ElasticsearchEx) IndexRefresh(ctx context.Context, index string) (*ShardsResponse, error) {
+	var rsp ShardsResponse
+	if err := doGetResponse[ErrGeneric](ctx, es.Indices.Refresh().Index(index), &rsp); err != nil {
+		return nil, err
+	}
+	return &rsp, checkShards("refresh", &rsp)
+}
+
+// IndexFlush persist the indexed documents of the indices to disk and clear the translog,
+// an ongoing flush is waited for instead of being skipped
+func (es *ElasticsearchEx) IndexFlush(ctx context.Context, index string) (*ShardsResponse, error) {
+	var rsp ShardsResponse
+	if err := doGetResponse[ErrGeneric](ctx, es.Indices.Flush().Index(index).WaitIfOngoing(true), &rsp); err != nil {
+		return nil, err
+	}
+	return &rsp, checkShards("flush", &rsp)
+}
+
+type forceMergeTaskResponse struct {
+	Completed bool            `json:"completed"`
+	Response  *ShardsResponse `json:"response"`
+	Error     ErrGeneric      `json:"error"`
+}
+
+func (f *forceMergeTaskResponse) FromJSON(bytes []byte) error {
+	return FromJSONImplDefault(bytes, f)
+}
+
+// IndexForceMerge merge the segments of the indices down to maxNumSegments, 0 to let es decide,
+// or only expunge the deleted documents. The merge can take hours on large indices, so it runs
+// as a background task, polled until done. It should only be run on indices no longer written to
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/indices-forcemerge.html
+func (es *ElasticsearchEx) IndexForceMerge(ctx context.Context, index string, maxNumSegments int, onlyExpungeDeletes bool) (*ShardsResponse, error) {
+	req := es.Indices.Forcemerge().Index(index).WaitForCompletion(false)
+	if maxNumSegments > 0 {
+		req.MaxNumSegments(strconv.Itoa(maxNumSegments))
+	}
+	if onlyExpungeDeletes {
+		req.OnlyExpungeDeletes(true)
+	}
+	var started taskStartResponse
+	if err := doGetResponse[ErrGeneric](ctx, req, &started); err != nil {
+		return nil, err
+	}
+
+	var rsp forceMergeTaskResponse
+	err := pollTask(ctx, defaultTaskPollInterval, func(ctx context.Context) (bool, error) {
+		rsp = forceMergeTaskResponse{}
+		if err := doGetResponse[ErrGeneric](ctx, es.Tasks.Get(started.Task), &rsp); err != nil {
+			return false, err
+		}
+		return rsp.Completed, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rsp.Error) > 0 {
+		return nil, rsp.Error
+	}
+	if rsp.Response == nil {
+		return nil, fmt.Errorf("task %s completed without response", started.Task)
+	}
+	return rsp.Response, checkShards("force merge", rsp.Response)
+}
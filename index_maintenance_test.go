@@ -0,0 +1,79 @@
+package elastic_wrapper
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/refresh"
+)
+
+func TestCheckShards(t *testing.T) {
+	var rsp ShardsResponse
+	body := `{"_shards":{"total":2,"successful":1,"failed":1,"failures":[` +
+		`{"shard":1,"index":"logs","status":"INTERNAL_SERVER_ERROR","reason":{"type":"merge_exception","reason":"disk full"}}]}}`
+	if err := rsp.FromJSON([]byte(body)); err != nil {
+		t.Fatal(err)
+	}
+	var errShards ErrShardFailures
+	if err := checkShards("force merge", &rsp); !errors.As(err, &errShards) {
+		t.Fatalf("expect ErrShardFailures, err=%v", err)
+	}
+	expect := "force merge failed on 1 of 2 shards, first failure: index=logs shard=1 type=merge_exception reason=disk full"
+	if errShards.Error() != expect {
+		t.Errorf("expect %s, got %s", expect, errShards.Error())
+	}
+
+	rsp.Shards = ShardsSummary{Total: 2, Successful: 2}
+	if err := checkShards("refresh", &rsp); err != nil {
+		t.Errorf("expect no error, err=%v", err)
+	}
+}
+
+func TestIndexMaintenance(t *testing.T) {
+	es := newClient(t)
+	ctx := context.Background()
+
+	demoIndex := "test_index_maintenance"
+
+	t.Cleanup(func() {
+		deleted, err := es.IndexDelete(context.Background(), demoIndex)
+		if err != nil {
+			t.Fatalf("delete index failed, err=%v", err)
+		}
+		if !deleted {
+			t.Fatalf("delete index failed, deleted=%v", deleted)
+		}
+	})
+
+	if _, err := es.IndexCreateSimple(ctx, demoIndex, nil); err != nil {
+		t.Fatalf("create simple index failed, err=%v", err)
+	}
+	for _, id := range []string{"1", "2", "3"} {
+		if _, err := es.DocCreateRefresh(ctx, demoIndex, id, map[string]any{"title": "doc " + id}, refresh.False); err != nil {
+			t.Fatalf("create doc failed, err=%v", err)
+		}
+	}
+
+	refreshed, err := es.IndexRefresh(ctx, demoIndex)
+	if err != nil {
+		t.Fatalf("refresh index failed, err=%v", err)
+	}
+	if refreshed.Shards.Successful == 0 {
+		t.Fatalf("expect successful shards, rsp=%+v", refreshed)
+	}
+	if _, err := es.IndexFlush(ctx, demoIndex); err != nil {
+		t.Fatalf("flush index failed, err=%v", err)
+	}
+	merged, err := es.IndexForceMerge(ctx, demoIndex, 1, false)
+	if err != nil {
+		t.Fatalf("force merge index failed, err=%v", err)
+	}
+	t.Logf("merged=%+v", merged)
+	if merged.Shards.Successful == 0 {
+		t.Fatalf("expect successful shards, rsp=%+v", merged)
+	}
+	if _, err := es.IndexForceMerge(ctx, demoIndex, 0, true); err != nil {
+		t.Fatalf("expunge deletes failed, err=%v", err)
+	}
+}
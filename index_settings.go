@@ -0,0 +1,185 @@
+package elastic_wrapper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+)
+
+type flatSettingsResponse map[string]struct {
+	Settings map[string]any `json:"settings"`
+}
+
+func (f *flatSettingsResponse) FromJSON(bytes []byte) error {
+	return FromJSONImplDefault(bytes, f)
+}
+
+// indexFlatSettings the settings of a single index or alias with flat keys, e.g. index.number_of_replicas
+func (es *ElasticsearchEx) indexFlatSettings(ctx context.Context, index string) (map[string]any, error) {
+	var rsp flatSettingsResponse
+	if err := doGetResponse[ErrGeneric](ctx, es.Indices.GetSettings().Index(index).FlatSettings(true), &rsp); err != nil {
+		return nil, err
+	}
+	// the response is keyed by the concrete index if index is an alias
+	if len(rsp) != 1 {
+		return nil, fmt.Errorf("settings of a single index expected, %s resolves to %d indices", index, len(rsp))
+	}
+	for _, settings := range rsp {
+		return settings.Settings, nil
+	}
+	return nil, nil
+}
+
+// IndexPutSettings update the dynamic settings of the index, wildcards and aliases are supported.
+// Static settings like number_of_shards can only be changed on a closed index
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/indices-update-settings.html
+func (es *ElasticsearchEx) IndexPutSettings(ctx context.Context, index string, settings *types.IndexSettings) (*AcknowledgedResponse, error) {
+	body, err := json.Marshal(settings)
+	if err != nil {
+		return nil, err
+	}
+	var rsp AcknowledgedResponse
+	err = doGetResponse[ErrGeneric](ctx, es.Indices.PutSettings().Index(index).Raw(body), &rsp)
+	return &rsp, err
+}
+
+// IndexPutSettingsRaw update the settings by flat or nested keys, e.g. index.refresh_interval,
+// a nil value resets the setting to its default
+func (es *ElasticsearchEx) IndexPutSettingsRaw(ctx context.Context, index string, settings map[string]any) (*AcknowledgedResponse, error) {
+	body, err := json.Marshal(settings)
+	if err != nil {
+		return nil, err
+	}
+	var rsp AcknowledgedResponse
+	err = doGetResponse[ErrGeneric](ctx, es.Indices.PutSettings().Index(index).Raw(body), &rsp)
+	return &rsp, err
+}
+
+// IndexSettingsInfo the common settings of an index, typed. A field is the zero value if the setting is neither
+// explicitly set nor returned as a default, see WithIncludeDefaults
+type IndexSettingsInfo struct {
+	NumberOfShards     int
+	NumberOfReplicas   int
+	AutoExpandReplicas string
+	RefreshInterval    string // e.g. 1s, -1 disables refresh
+	UUID               string
+	ProvidedName       string
+	CreationDate       time.Time
+	VersionCreated     string         // the internal id of the es version the index was created with
+	LifecycleName      string         // the ILM policy
+	DefaultPipeline    string         // the ingest pipeline
+	Analysis           map[string]any // analyzer, tokenizer, filter, char_filter and normalizer by name
+
+	Raw      map[string]any // the explicit settings as returned, with flat keys if WithFlatSettings
+	Defaults map[string]any // the defaults of the settings not explicitly set as returned, only WithIncludeDefaults
+
+	flat         map[string]any
+	flatDefaults map[string]any
+}
+
+// Get the value of the setting by flat key, e.g. index.refresh_interval, the explicit value or else the default
+func (s *IndexSettingsInfo) Get(key string) (any, bool) {
+	if v, ok := s.flat[key]; ok {
+		return v, true
+	}
+	v, ok := s.flatDefaults[key]
+	return v, ok
+}
+
+func (s *IndexSettingsInfo) getString(key string) string {
+	v, ok := s.Get(key)
+	if !ok || v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+func (s *IndexSettingsInfo) getInt(key string) (int, error) {
+	v := s.getString(key)
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("setting %s: %w", key, err)
+	}
+	return n, nil
+}
+
+// parseIndexSettings settings and defaults are either nested or flat, depending on flat_settings
+func parseIndexSettings(settings, defaults map[string]any) (*IndexSettingsInfo, error) {
+	s := &IndexSettingsInfo{
+		Raw:          settings,
+		Defaults:     defaults,
+		flat:         make(map[string]any),
+		flatDefaults: make(map[string]any),
+	}
+	flattenSettings("", settings, s.flat)
+	flattenSettings("", defaults, s.flatDefaults)
+
+	var err error
+	if s.NumberOfShards, err = s.getInt("index.number_of_shards"); err != nil {
+		return nil, err
+	}
+	if s.NumberOfReplicas, err = s.getInt("index.number_of_replicas"); err != nil {
+		return nil, err
+	}
+	s.AutoExpandReplicas = s.getString("index.auto_expand_replicas")
+	s.RefreshInterval = s.getString("index.refresh_interval")
+	s.UUID = s.getString("index.uuid")
+	s.ProvidedName = s.getString("index.provided_name")
+	s.VersionCreated = s.getString("index.version.created")
+	s.LifecycleName = s.getString("index.lifecycle.name")
+	s.DefaultPipeline = s.getString("index.default_pipeline")
+	if created := s.getString("index.creation_date"); created != "" {
+		millis, err := strconv.ParseInt(created, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("setting index.creation_date: %w", err)
+		}
+		s.CreationDate = time.UnixMilli(millis)
+	}
+	s.Analysis = unflattenSettings("index.analysis.", s.flat)
+	return s, nil
+}
+
+// flattenSettings the nested settings to flat keys, flat keys are kept as is
+func flattenSettings(prefix string, settings map[string]any, out map[string]any) {
+	for k, v := range settings {
+		key := joinPath(prefix, k)
+		if m, ok := v.(map[string]any); ok {
+			flattenSettings(key, m, out)
+			continue
+		}
+		out[key] = v
+	}
+}
+
+// unflattenSettings the nested settings of the flat keys with the prefix, nil if none
+func unflattenSettings(prefix string, flat map[string]any) map[string]any {
+	var nested map[string]any
+	for key, v := range flat {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		if nested == nil {
+			nested = make(map[string]any)
+		}
+		parts := strings.Split(strings.TrimPrefix(key, prefix), ".")
+		m := nested
+		for _, part := range parts[:len(parts)-1] {
+			sub, ok := m[part].(map[string]any)
+			if !ok {
+				sub = make(map[string]any)
+				m[part] = sub
+			}
+			m = sub
+		}
+		m[parts[len(parts)-1]] = v
+	}
+	return nested
+}
@@ -0,0 +1,53 @@
+package elastic_wrapper
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseIndexSettings(t *testing.T) {
+	nested := `{"index": {
+		"number_of_shards": "3",
+		"number_of_replicas": "1",
+		"uuid": "abc",
+		"provided_name": "articles",
+		"creation_date": "1672531200000",
+		"version": {"created": "8060099"},
+		"analysis": {"analyzer": {"my_english": {"type": "custom", "tokenizer": "standard", "filter": ["lowercase"]}}}
+	}}`
+	flat := `{
+		"index.number_of_shards": "3",
+		"index.number_of_replicas": "1",
+		"index.uuid": "abc",
+		"index.provided_name": "articles",
+		"index.creation_date": "1672531200000",
+		"index.version.created": "8060099",
+		"index.analysis.analyzer.my_english.type": "custom",
+		"index.analysis.analyzer.my_english.tokenizer": "standard",
+		"index.analysis.analyzer.my_english.filter": ["lowercase"]
+	}`
+	defaults := map[string]any{"index": map[string]any{"refresh_interval": "1s", "number_of_replicas": "9"}}
+
+	for name, raw := range map[string]string{"nested": nested, "flat": flat} {
+		var settings map[string]any
+		if err := json.Unmarshal([]byte(raw), &settings); err != nil {
+			t.Fatal(err)
+		}
+		s, err := parseIndexSettings(settings, defaults)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if s.NumberOfShards != 3 || s.NumberOfReplicas != 1 || s.UUID != "abc" || s.ProvidedName != "articles" ||
+			s.VersionCreated != "8060099" || s.CreationDate.UnixMilli() != 1672531200000 {
+			t.Errorf("%s: unexpected settings %+v", name, s)
+		}
+		// not set explicitly, taken from the defaults
+		if s.RefreshInterval != "1s" {
+			t.Errorf("%s: expect the default refresh_interval, got %q", name, s.RefreshInterval)
+		}
+		analyzer, _ := s.Analysis["analyzer"].(map[string]any)["my_english"].(map[string]any)
+		if analyzer["tokenizer"] != "standard" {
+			t.Errorf("%s: unexpected analysis %v", name, s.Analysis)
+		}
+	}
+}
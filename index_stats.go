@@ -0,0 +1,233 @@
+package elastic_wrapper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/dustin/go-humanize"
+)
+
+// IndexStats the statistics of the indices, FetchedAt is the local time the snapshot was taken, see Rate
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/indices-stats.html
+type IndexStats struct {
+	Shards struct {
+		Total      int `json:"total"`
+		Successful int `json:"successful"`
+		Failed     int `json:"failed"`
+	} `json:"_shards"`
+	All     IndexStatsGroup            `json:"_all"`
+	Indices map[string]IndexStatsGroup `json:"indices"`
+
+	FetchedAt time.Time `json:"-"`
+}
+
+func (i *IndexStats) FromJSON(bytes []byte) error {
+	return json.Unmarshal(bytes, i)
+}
+
+// IndexStatsGroup the statistics of the primary shards and of all the shards including the replicas
+type IndexStatsGroup struct {
+	UUID      string          `json:"uuid"`   // empty for _all
+	Health    string          `json:"health"` // empty for _all
+	Status    string          `json:"status"` // empty for _all
+	Primaries IndexStatsEntry `json:"primaries"`
+	Total     IndexStatsEntry `json:"total"`
+}
+
+type IndexStatsEntry struct {
+	Docs       DocsStats       `json:"docs"`
+	Store      StoreStats      `json:"store"`
+	Indexing   IndexingStats   `json:"indexing"`
+	Search     SearchStats     `json:"search"`
+	Merges     MergesStats     `json:"merges"`
+	Refresh    RefreshStats    `json:"refresh"`
+	Segments   SegmentsStats   `json:"segments"`
+	QueryCache QueryCacheStats `json:"query_cache"`
+}
+
+type DocsStats struct {
+	Count   int64 `json:"count"`
+	Deleted int64 `json:"deleted"`
+}
+
+type StoreStats struct {
+	SizeInBytes             int64 `json:"size_in_bytes"`
+	TotalDataSetSizeInBytes int64 `json:"total_data_set_size_in_bytes"`
+	ReservedInBytes         int64 `json:"reserved_in_bytes"`
+}
+
+type IndexingStats struct {
+	IndexTotal           int64 `json:"index_total"`
+	IndexTimeInMillis    int64 `json:"index_time_in_millis"`
+	IndexCurrent         int64 `json:"index_current"`
+	IndexFailed          int64 `json:"index_failed"`
+	DeleteTotal          int64 `json:"delete_total"`
+	DeleteTimeInMillis   int64 `json:"delete_time_in_millis"`
+	DeleteCurrent        int64 `json:"delete_current"`
+	NoopUpdateTotal      int64 `json:"noop_update_total"`
+	IsThrottled          bool  `json:"is_throttled"`
+	ThrottleTimeInMillis int64 `json:"throttle_time_in_millis"`
+}
+
+type SearchStats struct {
+	OpenContexts       int64 `json:"open_contexts"`
+	QueryTotal         int64 `json:"query_total"`
+	QueryTimeInMillis  int64 `json:"query_time_in_millis"`
+	QueryCurrent       int64 `json:"query_current"`
+	FetchTotal         int64 `json:"fetch_total"`
+	FetchTimeInMillis  int64 `json:"fetch_time_in_millis"`
+	FetchCurrent       int64 `json:"fetch_current"`
+	ScrollTotal        int64 `json:"scroll_total"`
+	ScrollTimeInMillis int64 `json:"scroll_time_in_millis"`
+	ScrollCurrent      int64 `json:"scroll_current"`
+}
+
+type MergesStats struct {
+	Current                    int64 `json:"current"`
+	CurrentDocs                int64 `json:"current_docs"`
+	CurrentSizeInBytes         int64 `json:"current_size_in_bytes"`
+	Total                      int64 `json:"total"`
+	TotalTimeInMillis          int64 `json:"total_time_in_millis"`
+	TotalDocs                  int64 `json:"total_docs"`
+	TotalSizeInBytes           int64 `json:"total_size_in_bytes"`
+	TotalThrottledTimeInMillis int64 `json:"total_throttled_time_in_millis"`
+}
+
+type RefreshStats struct {
+	Total                     int64 `json:"total"`
+	TotalTimeInMillis         int64 `json:"total_time_in_millis"`
+	ExternalTotal             int64 `json:"external_total"`
+	ExternalTotalTimeInMillis int64 `json:"external_total_time_in_millis"`
+	Listeners                 int64 `json:"listeners"`
+}
+
+type SegmentsStats struct {
+	Count                    int64 `json:"count"`
+	MemoryInBytes            int64 `json:"memory_in_bytes"`
+	IndexWriterMemoryInBytes int64 `json:"index_writer_memory_in_bytes"`
+	VersionMapMemoryInBytes  int64 `json:"version_map_memory_in_bytes"`
+	FixedBitSetMemoryInBytes int64 `json:"fixed_bit_set_memory_in_bytes"`
+	MaxUnsafeAutoIDTimestamp int64 `json:"max_unsafe_auto_id_timestamp"`
+}
+
+type QueryCacheStats struct {
+	MemorySizeInBytes int64 `json:"memory_size_in_bytes"`
+	TotalCount        int64 `json:"total_count"`
+	HitCount          int64 `json:"hit_count"`
+	MissCount         int64 `json:"miss_count"`
+	CacheSize         int64 `json:"cache_size"`
+	CacheCount        int64 `json:"cache_count"`
+	Evictions         int64 `json:"evictions"`
+}
+
+// HitRatio the ratio of the cache hits among the lookups, 0 if never looked up
+func (q QueryCacheStats) HitRatio() float64 {
+	if q.HitCount+q.MissCount == 0 {
+		return 0
+	}
+	return float64(q.HitCount) / float64(q.HitCount+q.MissCount)
+}
+
+// IndexStats the statistics of the index, wildcards and comma separated lists are supported, all indices if empty
+func (es *ElasticsearchEx) IndexStats(ctx context.Context, indexName string) (*IndexStats, error) {
+	req := es.Indices.Stats()
+	if indexName != "" {
+		req.Index(indexName)
+	}
+	var rsp IndexStats
+	err := doGetResponse[ErrGeneric](ctx, req, &rsp)
+	rsp.FetchedAt = time.Now()
+	return &rsp, err
+}
+
+func (e IndexStatsEntry) String() string {
+	return fmt.Sprintf("docs=%s (deleted %s) store=%s indexing=%s ops in %s search=%s queries in %s "+
+		"merges=%s in %s refresh=%s in %s segments=%s (%s) query_cache=%s hit %.1f%%",
+		humanize.Comma(e.Docs.Count), humanize.Comma(e.Docs.Deleted),
+		humanize.IBytes(uint64(e.Store.SizeInBytes)),
+		humanize.Comma(e.Indexing.IndexTotal), millis(e.Indexing.IndexTimeInMillis),
+		humanize.Comma(e.Search.QueryTotal), millis(e.Search.QueryTimeInMillis),
+		humanize.Comma(e.Merges.Total), millis(e.Merges.TotalTimeInMillis),
+		humanize.Comma(e.Refresh.Total), millis(e.Refresh.TotalTimeInMillis),
+		humanize.Comma(e.Segments.Count), humanize.IBytes(uint64(e.Segments.MemoryInBytes)),
+		humanize.IBytes(uint64(e.QueryCache.MemorySizeInBytes)), e.QueryCache.HitRatio()*100)
+}
+
+func millis(ms int64) time.Duration {
+	return time.Duration(ms) * time.Millisecond
+}
+
+// IndexStatsRate the rates between two snapshots of the same index
+type IndexStatsRate struct {
+	Elapsed time.Duration
+
+	DocsDelta  int64
+	StoreDelta int64 // bytes
+
+	IndexingPerSec  float64       // index operations per second
+	IndexingLatency time.Duration // the average time of an index operation
+	QueriesPerSec   float64       // search queries per second
+	QueryLatency    time.Duration // the average time of a search query
+	FetchLatency    time.Duration // the average time of a search fetch
+	RefreshesPerSec float64
+	MergesPerSec    float64
+}
+
+func (r IndexStatsRate) String() string {
+	return fmt.Sprintf("over %s: docs %+d store %s indexing %.1f/s (avg %s) search %.1f/s (query avg %s, fetch avg %s) "+
+		"refresh %.2f/s merges %.2f/s",
+		r.Elapsed.Round(time.Millisecond), r.DocsDelta, signedBytes(r.StoreDelta),
+		r.IndexingPerSec, r.IndexingLatency, r.QueriesPerSec, r.QueryLatency, r.FetchLatency,
+		r.RefreshesPerSec, r.MergesPerSec)
+}
+
+func signedBytes(b int64) string {
+	if b < 0 {
+		return "-" + humanize.IBytes(uint64(-b))
+	}
+	return "+" + humanize.IBytes(uint64(b))
+}
+
+// Rate the rates from the earlier snapshot prev to e over elapsed, a counter going backwards (e.g. a restarted
+// node or a recreated index) gives a zero rate
+func (e IndexStatsEntry) Rate(prev IndexStatsEntry, elapsed time.Duration) IndexStatsRate {
+	rate := IndexStatsRate{
+		Elapsed:    elapsed,
+		DocsDelta:  e.Docs.Count - prev.Docs.Count,
+		StoreDelta: e.Store.SizeInBytes - prev.Store.SizeInBytes,
+	}
+	indexOps := counterDelta(e.Indexing.IndexTotal, prev.Indexing.IndexTotal)
+	queries := counterDelta(e.Search.QueryTotal, prev.Search.QueryTotal)
+	fetches := counterDelta(e.Search.FetchTotal, prev.Search.FetchTotal)
+	rate.IndexingLatency = averageLatency(counterDelta(e.Indexing.IndexTimeInMillis, prev.Indexing.IndexTimeInMillis), indexOps)
+	rate.QueryLatency = averageLatency(counterDelta(e.Search.QueryTimeInMillis, prev.Search.QueryTimeInMillis), queries)
+	rate.FetchLatency = averageLatency(counterDelta(e.Search.FetchTimeInMillis, prev.Search.FetchTimeInMillis), fetches)
+	if seconds := elapsed.Seconds(); seconds > 0 {
+		rate.IndexingPerSec = float64(indexOps) / seconds
+		rate.QueriesPerSec = float64(queries) / seconds
+		rate.RefreshesPerSec = float64(counterDelta(e.Refresh.Total, prev.Refresh.Total)) / seconds
+		rate.MergesPerSec = float64(counterDelta(e.Merges.Total, prev.Merges.Total)) / seconds
+	}
+	return rate
+}
+
+// Rate the rates of all the shards of the indices (_all.total) since the earlier snapshot prev
+func (i *IndexStats) Rate(prev *IndexStats) IndexStatsRate {
+	return i.All.Total.Rate(prev.All.Total, i.FetchedAt.Sub(prev.FetchedAt))
+}
+
+func counterDelta(cur, prev int64) int64 {
+	if cur < prev {
+		return 0
+	}
+	return cur - prev
+}
+
+func averageLatency(totalMillis, count int64) time.Duration {
+	if count == 0 {
+		return 0
+	}
+	return millis(totalMillis) / time.Duration(count)
+}
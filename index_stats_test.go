@@ -0,0 +1,57 @@
+package elastic_wrapper
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIndexStatsRate(t *testing.T) {
+	var prev, cur IndexStats
+	if err := prev.FromJSON([]byte(`{"_all": {"total": {
+		"docs": {"count": 1000, "deleted": 10},
+		"store": {"size_in_bytes": 1048576},
+		"indexing": {"index_total": 1000, "index_time_in_millis": 2000},
+		"search": {"query_total": 100, "query_time_in_millis": 500, "fetch_total": 50, "fetch_time_in_millis": 100},
+		"refresh": {"total": 10},
+		"query_cache": {"hit_count": 3, "miss_count": 1}
+	}}}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := cur.FromJSON([]byte(`{"_all": {"total": {
+		"docs": {"count": 1500, "deleted": 10},
+		"store": {"size_in_bytes": 2097152},
+		"indexing": {"index_total": 2000, "index_time_in_millis": 3000},
+		"search": {"query_total": 300, "query_time_in_millis": 1500, "fetch_total": 50, "fetch_time_in_millis": 100},
+		"refresh": {"total": 20},
+		"query_cache": {"hit_count": 3, "miss_count": 1}
+	}}}`)); err != nil {
+		t.Fatal(err)
+	}
+	prev.FetchedAt = time.Unix(0, 0)
+	cur.FetchedAt = prev.FetchedAt.Add(10 * time.Second)
+
+	rate := cur.Rate(&prev)
+	t.Log(rate)
+	t.Log(cur.All.Total)
+	if rate.DocsDelta != 500 || rate.StoreDelta != 1048576 {
+		t.Errorf("unexpected deltas %+v", rate)
+	}
+	if rate.IndexingPerSec != 100 || rate.IndexingLatency != time.Millisecond {
+		t.Errorf("unexpected indexing rate %+v", rate)
+	}
+	if rate.QueriesPerSec != 20 || rate.QueryLatency != 5*time.Millisecond || rate.FetchLatency != 0 {
+		t.Errorf("unexpected search rate %+v", rate)
+	}
+	if rate.RefreshesPerSec != 1 {
+		t.Errorf("unexpected refresh rate %+v", rate)
+	}
+	if !strings.Contains(cur.All.Total.String(), "docs=1,500") || !strings.Contains(cur.All.Total.String(), "hit 75.0%") {
+		t.Errorf("unexpected String %s", cur.All.Total)
+	}
+
+	// a counter going backwards gives a zero rate
+	if rate := prev.Rate(&cur); rate.IndexingPerSec != 0 {
+		t.Errorf("expect zero rate, got %+v", rate)
+	}
+}
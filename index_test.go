@@ -79,13 +79,28 @@ func TestIndexCreateRawMustSuccess(t *testing.T) {
 		t.Fatalf("IndexGet failed, err=%v", err)
 	}
 	t.Logf("info=%+v", info)
+	settings := (*info)[demoIndex].Settings
+	if settings == nil || settings.NumberOfShards < 1 || settings.UUID == "" || settings.CreationDate.IsZero() {
+		t.Fatalf("IndexGet failed, settings=%+v", settings)
+	}
+
+	info, err = es.IndexGet(context.Background(), demoIndex, WithFlatSettings(), WithIncludeDefaults())
+	if err != nil {
+		t.Fatalf("IndexGet failed, err=%v", err)
+	}
+	if settings := (*info)[demoIndex].Settings; settings.RefreshInterval == "" {
+		t.Fatalf("IndexGet with defaults failed, settings=%+v", settings)
+	}
 
 	// test get index stats
 	stats, err := es.IndexStats(context.Background(), demoIndex)
 	if err != nil {
 		t.Fatalf("IndexStats failed, err=%v", err)
 	}
-	t.Logf("stats=%+v", stats)
+	t.Logf("stats=%s", stats.Indices[demoIndex].Total)
+	if _, ok := stats.Indices[demoIndex]; !ok {
+		t.Fatalf("IndexStats failed, no stats of %s", demoIndex)
+	}
 }
 
 // test TestIndexCreateMustFailEs8 Must Fail
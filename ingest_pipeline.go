@@ -0,0 +1,139 @@
+package elastic_wrapper
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/ingest/putpipeline"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/ingest/simulate"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+)
+
+type AcknowledgedResponse struct {
+	Acknowledged bool `json:"acknowledged"`
+}
+
+func (a *AcknowledgedResponse) FromJSON(bytes []byte) error {
+	return FromJSONImplDefault(bytes, a)
+}
+
+// PutPipeline create or update ingest pipeline
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/put-pipeline-api.html
+func (es *ElasticsearchEx) PutPipeline(ctx context.Context, id string, pipeline *types.IngestPipeline) (*AcknowledgedResponse, error) {
+	request := putpipeline.NewRequest()
+	request.Description = pipeline.Description
+	request.OnFailure = pipeline.OnFailure
+	request.Processors = pipeline.Processors
+	request.Version = pipeline.Version
+	req := es.Ingest.PutPipeline(id).Request(request)
+	var rsp AcknowledgedResponse
+	err := doGetResponse[ErrGeneric](ctx, req, &rsp)
+	return &rsp, err
+}
+
+// PutPipelineRaw create or update ingest pipeline by the JSON definition
+func (es *ElasticsearchEx) PutPipelineRaw(ctx context.Context, id string, pipeline []byte) (*AcknowledgedResponse, error) {
+	req := es.Ingest.PutPipeline(id).Raw(pipeline)
+	var rsp AcknowledgedResponse
+	err := doGetResponse[ErrGeneric](ctx, req, &rsp)
+	return &rsp, err
+}
+
+// GetPipelineResponse pipeline id -> pipeline
+type GetPipelineResponse map[string]types.IngestPipeline
+
+func (g *GetPipelineResponse) FromJSON(bytes []byte) error {
+	return FromJSONImplDefault(bytes, g)
+}
+
+// GetPipeline get ingest pipelines by ids, wildcards are supported, all pipelines if no id given
+// ErrNotFound if none matches
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/get-pipeline-api.html
+func (es *ElasticsearchEx) GetPipeline(ctx context.Context, ids ...string) (GetPipelineResponse, error) {
+	req := es.Ingest.GetPipeline()
+	if len(ids) > 0 {
+		req.Id(strings.Join(ids, ","))
+	}
+	var rsp GetPipelineResponse
+	err := doGetResponse[ErrGeneric](ctx, req, &rsp)
+	return rsp, err
+}
+
+// DeletePipeline delete ingest pipeline by id, ErrNotFound if not exists
+func (es *ElasticsearchEx) DeletePipeline(ctx context.Context, id string) (*AcknowledgedResponse, error) {
+	req := es.Ingest.DeletePipeline(id)
+	var rsp AcknowledgedResponse
+	err := doGetResponse[ErrGeneric](ctx, req, &rsp)
+	return &rsp, err
+}
+
+// SimulateDocument the document after the pipeline (or the processor in verbose mode) ran
+type SimulateDocument struct {
+	Index   string         `json:"_index"`
+	Id      string         `json:"_id"`
+	Routing string         `json:"_routing"`
+	Source  map[string]any `json:"_source"`
+	Ingest  struct {
+		Timestamp string `json:"timestamp"`
+		Pipeline  string `json:"pipeline"`
+	} `json:"_ingest"`
+}
+
+// SimulateProcessorResult the result of one processor in verbose mode
+type SimulateProcessorResult struct {
+	ProcessorType string `json:"processor_type"`
+	Status        string `json:"status"` // success, error, error_ignored, skipped, dropped
+	Tag           string `json:"tag"`
+	Description   string `json:"description"`
+	If            *struct {
+		Condition string `json:"condition"`
+		Result    bool   `json:"result"`
+	} `json:"if"`
+	Doc          *SimulateDocument `json:"doc"`
+	Error        *ErrorCause       `json:"error"`
+	IgnoredError *ErrorCause       `json:"ignored_error"`
+}
+
+// SimulatePipelineDocResult the result of one document, ProcessorResults is only set in verbose mode
+type SimulatePipelineDocResult struct {
+	Doc              *SimulateDocument         `json:"doc"`
+	Error            *ErrorCause               `json:"error"`
+	ProcessorResults []SimulateProcessorResult `json:"processor_results"`
+}
+
+type SimulatePipelineResponse struct {
+	Docs []SimulatePipelineDocResult `json:"docs"`
+}
+
+func (s *SimulatePipelineResponse) FromJSON(bytes []byte) error {
+	return FromJSONImplDefault(bytes, s)
+}
+
+// SimulatePipeline run the docs (as _source) through the stored pipeline id, or the inline pipeline if id is empty.
+// In verbose mode the document after every processor is returned
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/simulate-pipeline-api.html
+func (es *ElasticsearchEx) SimulatePipeline(ctx context.Context, id string, pipeline *types.IngestPipeline, docs []any, verbose bool) (*SimulatePipelineResponse, error) {
+	if id == "" && pipeline == nil {
+		return nil, fmt.Errorf("simulate pipeline needs either a pipeline id or an inline pipeline")
+	}
+	request := simulate.NewRequest()
+	if id == "" {
+		request.Pipeline = pipeline
+	}
+	request.Docs = make([]types.Document, 0, len(docs))
+	for _, doc := range docs {
+		request.Docs = append(request.Docs, types.Document{Source_: doc})
+	}
+
+	req := es.Ingest.Simulate().Request(request)
+	if id != "" {
+		req.Id(id)
+	}
+	if verbose {
+		req.Verbose(true)
+	}
+	var rsp SimulatePipelineResponse
+	err := doGetResponse[ErrGeneric](ctx, req, &rsp)
+	return &rsp, err
+}
@@ -0,0 +1,87 @@
+package elastic_wrapper
+
+import (
+	"context"
+	"testing"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+)
+
+func TestIngestPipeline(t *testing.T) {
+	es := newClient(t)
+
+	demoIndex := "test_ingest_pipeline"
+	pipelineID := "test_set_source_pipeline"
+
+	t.Cleanup(func() {
+		deleted, err := es.IndexDelete(context.Background(), demoIndex)
+		if err != nil {
+			t.Fatalf("delete index failed, err=%v", err)
+		}
+		if !deleted {
+			t.Fatalf("delete index failed, deleted=%v", deleted)
+		}
+		if _, err := es.DeletePipeline(context.Background(), pipelineID); err != nil {
+			t.Fatalf("delete pipeline failed, err=%v", err)
+		}
+	})
+	rsp, err := es.IndexCreateSimple(context.Background(), demoIndex, &types.TypeMapping{Properties: map[string]types.Property{
+		"content": types.NewKeywordProperty(),
+		"source":  types.NewKeywordProperty(),
+	}})
+	if err != nil {
+		t.Fatalf("create simple index failed, err=%v", err)
+	}
+	t.Logf("rsp=%+v", rsp)
+
+	description := "set the source field"
+	pipeline := &types.IngestPipeline{
+		Description: &description,
+		Processors: []types.ProcessorContainer{
+			{Set: &types.SetProcessor{Field: "source", Value: "pipeline"}},
+		},
+	}
+	ack, err := es.PutPipeline(context.Background(), pipelineID, pipeline)
+	if err != nil {
+		t.Fatalf("put pipeline failed, err=%v", err)
+	}
+	if !ack.Acknowledged {
+		t.Fatalf("put pipeline not acknowledged")
+	}
+
+	pipelines, err := es.GetPipeline(context.Background(), pipelineID)
+	if err != nil {
+		t.Fatalf("get pipeline failed, err=%v", err)
+	}
+	if _, ok := pipelines[pipelineID]; !ok {
+		t.Fatalf("get pipeline failed, pipelines=%+v", pipelines)
+	}
+
+	simulated, err := es.SimulatePipeline(context.Background(), pipelineID, nil, []any{map[string]any{"content": "hello"}}, true)
+	if err != nil {
+		t.Fatalf("simulate pipeline failed, err=%v", err)
+	}
+	t.Logf("simulated=%+v", simulated)
+	if len(simulated.Docs) != 1 || len(simulated.Docs[0].ProcessorResults) != 1 {
+		t.Fatalf("simulate pipeline failed, docs=%+v", simulated.Docs)
+	}
+	if doc := simulated.Docs[0].ProcessorResults[0].Doc; doc == nil || doc.Source["source"] != "pipeline" {
+		t.Fatalf("simulate pipeline failed, doc=%+v", doc)
+	}
+
+	_, err = es.DocIndexSimple(context.Background(), demoIndex, "1", map[string]any{"content": "hello"}, WithPipeline(pipelineID))
+	if err != nil {
+		t.Fatalf("index doc failed, err=%v", err)
+	}
+	type DemoDoc struct {
+		Content string `json:"content"`
+		Source  string `json:"source"`
+	}
+	doc, err := DocGetSource[DemoDoc](context.Background(), es, demoIndex, "1")
+	if err != nil {
+		t.Fatalf("get doc failed, err=%v", err)
+	}
+	if doc.Source != "pipeline" {
+		t.Fatalf("pipeline not applied, doc=%+v", doc)
+	}
+}
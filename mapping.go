@@ -0,0 +1,320 @@
+package elastic_wrapper
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/dynamicmapping"
+)
+
+// the default format of date fields, which matches the RFC 3339 output of time.Time and epoch millis
+const defaultDateFormat = "strict_date_optional_time||epoch_millis"
+
+// the ignore_above of the `.keyword` sub field, the same as the es dynamic mapping
+const defaultKeywordIgnoreAbove = 256
+
+var timeType = reflect.TypeOf(time.Time{})
+
+var mappingProperties = map[string]func() types.Property{
+	"keyword":       func() types.Property { return types.NewKeywordProperty() },
+	"text":          func() types.Property { return types.NewTextProperty() },
+	"wildcard":      func() types.Property { return types.NewWildcardProperty() },
+	"date":          func() types.Property { return types.NewDateProperty() },
+	"date_nanos":    func() types.Property { return types.NewDateNanosProperty() },
+	"boolean":       func() types.Property { return types.NewBooleanProperty() },
+	"byte":          func() types.Property { return types.NewByteNumberProperty() },
+	"short":         func() types.Property { return types.NewShortNumberProperty() },
+	"integer":       func() types.Property { return types.NewIntegerNumberProperty() },
+	"long":          func() types.Property { return types.NewLongNumberProperty() },
+	"unsigned_long": func() types.Property { return types.NewUnsignedLongNumberProperty() },
+	"half_float":    func() types.Property { return types.NewHalfFloatNumberProperty() },
+	"float":         func() types.Property { return types.NewFloatNumberProperty() },
+	"double":        func() types.Property { return types.NewDoubleNumberProperty() },
+	"scaled_float":  func() types.Property { return types.NewScaledFloatNumberProperty() },
+	"binary":        func() types.Property { return types.NewBinaryProperty() },
+	"ip":            func() types.Property { return types.NewIpProperty() },
+	"geo_point":     func() types.Property { return types.NewGeoPointProperty() },
+	"flattened":     func() types.Property { return types.NewFlattenedProperty() },
+	"object":        func() types.Property { return types.NewObjectProperty() },
+	"nested":        func() types.Property { return types.NewNestedProperty() },
+}
+
+// the tag options which are set to the field of the same name of the property struct
+var mappingOptionFields = map[string]string{
+	"index":           "Index",
+	"doc_values":      "DocValues",
+	"store":           "Store",
+	"analyzer":        "Analyzer",
+	"search_analyzer": "SearchAnalyzer",
+	"normalizer":      "Normalizer",
+	"format":          "Format",
+	"ignore_above":    "IgnoreAbove",
+	"scaling_factor":  "ScalingFactor",
+	"dynamic":         "Dynamic",
+	"enabled":         "Enabled",
+}
+
+// MappingOption tune the root of the mapping generated by MappingFor
+type MappingOption func(*types.TypeMapping)
+
+// WithMappingDynamic the dynamic setting of the root object, dynamicmapping.Strict rejects unknown fields
+func WithMappingDynamic(dynamic dynamicmapping.DynamicMapping) MappingOption {
+	return func(m *types.TypeMapping) {
+		m.Dynamic = &dynamic
+	}
+}
+
+// MappingFor generate the index mapping of the struct T, the field names follow the json tags.
+// The `es` tag is `es:"type,option=value,..."`, `es:"-"` skips the field, an empty type is inferred from the go type:
+//
+//	string -> keyword, bool -> boolean, time.Time -> date, []byte -> binary,
+//	int8 -> byte, int16/uint8 -> short, int32/uint16 -> integer, int/int64/uint32 -> long, uint/uint64 -> unsigned_long,
+//	float32 -> float, float64 -> double, struct -> object, slice -> the type of the element, map -> object
+//
+// a slice of structs is an object unless the type is nested. Supported options:
+//
+//	index, doc_values, store, analyzer, search_analyzer, normalizer, format, ignore_above,
+//	scaling_factor, dynamic (object/nested), enabled (object),
+//	keyword or keyword=<ignore_above>: the `.keyword` multi-field of a text field
+//
+// e.g.
+//
+//	type Article struct {
+//		Title   string    `json:"title" es:"text,analyzer=ik_max_word,keyword"`
+//		Tags    []string  `json:"tags"`
+//		Cover   string    `json:"cover" es:"keyword,index=false"`
+//		Price   float64   `json:"price" es:"scaled_float,scaling_factor=100"`
+//		Created time.Time `json:"created" es:",format=epoch_millis"`
+//		Authors []Author  `json:"authors" es:"nested,dynamic=strict"`
+//	}
+func MappingFor[T any](opts ...MappingOption) (*types.TypeMapping, error) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("mapping of %s: not a struct", t)
+	}
+	properties, err := structProperties(t, map[reflect.Type]bool{})
+	if err != nil {
+		return nil, fmt.Errorf("mapping of %s: %w", t, err)
+	}
+	mapping := types.NewTypeMapping()
+	mapping.Properties = properties
+	for _, opt := range opts {
+		opt(mapping)
+	}
+	return mapping, nil
+}
+
+func structProperties(t reflect.Type, visiting map[reflect.Type]bool) (map[string]types.Property, error) {
+	if visiting[t] {
+		return nil, fmt.Errorf("recursive type %s", t)
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	properties := make(map[string]types.Property)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+		name, skip := jsonFieldName(field)
+		if skip || field.Tag.Get("es") == "-" {
+			continue
+		}
+		// embedded structs without a json name are flattened, the same as encoding/json
+		if field.Anonymous && name == "" {
+			ft := derefType(field.Type)
+			if ft.Kind() == reflect.Struct {
+				embedded, err := structProperties(ft, visiting)
+				if err != nil {
+					return nil, err
+				}
+				for k, v := range embedded {
+					if _, ok := properties[k]; !ok {
+						properties[k] = v
+					}
+				}
+				continue
+			}
+			if !field.IsExported() {
+				continue
+			}
+		}
+		if name == "" {
+			name = field.Name
+		}
+		property, err := fieldProperty(field.Type, field.Tag.Get("es"), visiting)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		properties[name] = property
+	}
+	return properties, nil
+}
+
+func jsonFieldName(field reflect.StructField) (name string, skip bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", true
+	}
+	name, _, _ = strings.Cut(tag, ",")
+	return name, false
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+func fieldProperty(t reflect.Type, tag string, visiting map[reflect.Type]bool) (types.Property, error) {
+	t = derefType(t)
+	// a slice is mapped as its element, except []byte which is binary
+	if (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && t.Elem().Kind() != reflect.Uint8 {
+		t = derefType(t.Elem())
+	}
+
+	typeName, options, _ := strings.Cut(tag, ",")
+	if typeName == "" {
+		typeName = inferFieldType(t)
+		if typeName == "" {
+			return nil, fmt.Errorf("cannot infer the es type of %s, set it by the es tag", t)
+		}
+	}
+	newProperty, ok := mappingProperties[typeName]
+	if !ok {
+		return nil, fmt.Errorf("unsupported es type %s", typeName)
+	}
+	property := newProperty()
+	pv := reflect.ValueOf(property).Elem()
+
+	if typeName == "date" || typeName == "date_nanos" {
+		format := defaultDateFormat
+		pv.FieldByName("Format").Set(reflect.ValueOf(&format))
+	}
+	if (typeName == "object" || typeName == "nested") && t.Kind() == reflect.Struct && t != timeType {
+		properties, err := structProperties(t, visiting)
+		if err != nil {
+			return nil, err
+		}
+		pv.FieldByName("Properties").Set(reflect.ValueOf(properties))
+	}
+
+	for _, option := range strings.Split(options, ",") {
+		if option == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(option, "=")
+		if key == "keyword" {
+			if typeName != "text" {
+				return nil, fmt.Errorf("option keyword is only supported by text, got %s", typeName)
+			}
+			ignoreAbove := defaultKeywordIgnoreAbove
+			if value != "" {
+				n, err := strconv.Atoi(value)
+				if err != nil {
+					return nil, fmt.Errorf("invalid option %s: %w", option, err)
+				}
+				ignoreAbove = n
+			}
+			keyword := types.NewKeywordProperty()
+			keyword.IgnoreAbove = &ignoreAbove
+			property.(*types.TextProperty).Fields["keyword"] = keyword
+			continue
+		}
+		fieldName, ok := mappingOptionFields[key]
+		if !ok {
+			return nil, fmt.Errorf("unknown option %s", key)
+		}
+		fv := pv.FieldByName(fieldName)
+		if !fv.IsValid() {
+			return nil, fmt.Errorf("option %s is not supported by %s", key, typeName)
+		}
+		if err := setMappingOption(fv, value); err != nil {
+			return nil, fmt.Errorf("invalid option %s: %w", option, err)
+		}
+	}
+	if sf, ok := property.(*types.ScaledFloatNumberProperty); ok && sf.ScalingFactor == nil {
+		return nil, fmt.Errorf("scaled_float requires the scaling_factor option")
+	}
+	return property, nil
+}
+
+func inferFieldType(t reflect.Type) string {
+	if t == timeType {
+		return "date"
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "keyword"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int8:
+		return "byte"
+	case reflect.Int16, reflect.Uint8:
+		return "short"
+	case reflect.Int32, reflect.Uint16:
+		return "integer"
+	case reflect.Int, reflect.Int64, reflect.Uint32:
+		return "long"
+	case reflect.Uint, reflect.Uint64:
+		return "unsigned_long"
+	case reflect.Float32:
+		return "float"
+	case reflect.Float64:
+		return "double"
+	case reflect.Slice, reflect.Array:
+		// only []byte reaches here
+		return "binary"
+	case reflect.Struct, reflect.Map:
+		return "object"
+	}
+	return ""
+}
+
+// setMappingOption set the option value to the pointer field fv of the property struct
+func setMappingOption(fv reflect.Value, value string) error {
+	v := reflect.New(fv.Type().Elem())
+	if u, ok := v.Interface().(encoding.TextUnmarshaler); ok {
+		if err := u.UnmarshalText([]byte(value)); err != nil {
+			return err
+		}
+		fv.Set(v)
+		return nil
+	}
+	switch v.Elem().Kind() {
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		v.Elem().SetBool(b)
+	case reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		v.Elem().SetInt(int64(n))
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		v.Elem().SetFloat(f)
+	case reflect.String:
+		v.Elem().SetString(value)
+	default:
+		return fmt.Errorf("unsupported value type %s", fv.Type())
+	}
+	fv.Set(v)
+	return nil
+}
@@ -0,0 +1,404 @@
+package elastic_wrapper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+)
+
+type MappingChangeKind string
+
+const (
+	// MappingChangeAdditive a new field or an updatable parameter, applied by the put mapping API
+	MappingChangeAdditive MappingChangeKind = "additive"
+	// MappingChangeConflicting an existing field can not be changed in place, the index needs a reindex
+	MappingChangeConflicting MappingChangeKind = "conflicting"
+	// MappingChangeIgnorable no real change, e.g. a field only in the live mapping, or a parameter set to its default
+	MappingChangeIgnorable MappingChangeKind = "ignorable"
+)
+
+// the parameters which can be updated on an existing field or on the root of the mapping
+var updatableMappingParams = map[string]bool{
+	"ignore_above":          true,
+	"search_analyzer":       true,
+	"search_quote_analyzer": true,
+	"ignore_malformed":      true,
+	"eager_global_ordinals": true,
+	"fielddata":             true,
+	"dynamic":               true,
+	"meta":                  true,
+	"_meta":                 true,
+	"date_detection":        true,
+	"numeric_detection":     true,
+	"dynamic_date_formats":  true,
+	"dynamic_templates":     true,
+}
+
+// the es defaults of the parameters, the live mapping omits a parameter set to its default,
+// so a desired parameter set to it explicitly is not a change
+var defaultMappingParams = map[string]any{
+	"index":      true,
+	"doc_values": true,
+	"store":      false,
+	"enabled":    true,
+	"norms":      true,
+	"analyzer":   "standard",
+	"format":     defaultDateFormat,
+}
+
+// MappingChange one difference between the live and the desired mapping
+type MappingChange struct {
+	Path    string // the dotted field path, multi-fields included, empty for the root of the mapping
+	Param   string // the changed parameter, empty if the change is about the whole field
+	Kind    MappingChangeKind
+	Live    any // nil if missing in the live mapping
+	Desired any // nil if missing in the desired mapping
+	Reason  string
+
+	keys []string // the location in the mapping JSON
+}
+
+func (c MappingChange) String() string {
+	path := c.Path
+	if path == "" {
+		path = "<root>"
+	}
+	if c.Param != "" {
+		path += " " + c.Param
+	}
+	return fmt.Sprintf("[%s] %s: %s", c.Kind, path, c.Reason)
+}
+
+// MappingDiff the classified changes from the live mapping of Index to the desired mapping
+type MappingDiff struct {
+	Index       string
+	Live        map[string]any     // the live mapping as returned by es, the base of the mapping ApplyMapping puts
+	LiveMapping *types.TypeMapping // the live mapping, typed
+	Changes     []MappingChange
+}
+
+func (d *MappingDiff) filter(kind MappingChangeKind) []MappingChange {
+	var changes []MappingChange
+	for _, c := range d.Changes {
+		if c.Kind == kind {
+			changes = append(changes, c)
+		}
+	}
+	return changes
+}
+
+func (d *MappingDiff) Additive() []MappingChange {
+	return d.filter(MappingChangeAdditive)
+}
+
+func (d *MappingDiff) Conflicting() []MappingChange {
+	return d.filter(MappingChangeConflicting)
+}
+
+func (d *MappingDiff) Ignorable() []MappingChange {
+	return d.filter(MappingChangeIgnorable)
+}
+
+// HasConflicts true if the desired mapping can only be reached by a reindex
+func (d *MappingDiff) HasConflicts() bool {
+	return len(d.Conflicting()) > 0
+}
+
+// String the report of all the changes, one change per line
+func (d *MappingDiff) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "mapping diff of %s: %d additive, %d conflicting, %d ignorable",
+		d.Index, len(d.Additive()), len(d.Conflicting()), len(d.Ignorable()))
+	for _, c := range d.Changes {
+		sb.WriteString("\n  ")
+		sb.WriteString(c.String())
+	}
+	return sb.String()
+}
+
+// ErrMappingConflicts the conflicting changes refused by ApplyMapping
+type ErrMappingConflicts struct {
+	Index   string
+	Changes []MappingChange
+}
+
+func (e ErrMappingConflicts) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "mapping of %s has %d conflicting changes which need a reindex:", e.Index, len(e.Changes))
+	for _, c := range e.Changes {
+		sb.WriteString("\n  ")
+		sb.WriteString(c.String())
+	}
+	return sb.String()
+}
+
+type getMappingResponse map[string]struct {
+	Mappings map[string]any `json:"mappings"`
+}
+
+func (g *getMappingResponse) FromJSON(bytes []byte) error {
+	return FromJSONImplDefault(bytes, g)
+}
+
+// liveMapping the mapping of the single index behind the index name or alias
+func (es *ElasticsearchEx) liveMapping(ctx context.Context, index string) (map[string]any, error) {
+	var rsp getMappingResponse
+	if err := doGetResponse[ErrGeneric](ctx, es.Indices.GetMapping().Index(index), &rsp); err != nil {
+		return nil, err
+	}
+	if len(rsp) != 1 {
+		return nil, fmt.Errorf("mapping diff needs exactly one index, %s resolves to %d indices", index, len(rsp))
+	}
+	for _, v := range rsp {
+		if v.Mappings == nil {
+			return map[string]any{}, nil
+		}
+		return v.Mappings, nil
+	}
+	return nil, nil
+}
+
+// DiffMapping compare the live mapping of the index with the desired mapping, e.g. the one from MappingFor.
+// New fields and updatable parameters are additive, changing the type or a fixed parameter of an existing field
+// is conflicting, fields only in the live mapping and parameters set to their defaults are ignorable
+func (es *ElasticsearchEx) DiffMapping(ctx context.Context, index string, desired *types.TypeMapping) (*MappingDiff, error) {
+	live, err := es.liveMapping(ctx, index)
+	if err != nil {
+		return nil, err
+	}
+	return diffMapping(index, live, desired)
+}
+
+// ApplyMapping put the additive changes of DiffMapping to the index, the conflicting changes are refused
+// and returned as ErrMappingConflicts, together with the diff. Nothing is put if there is no additive change
+func (es *ElasticsearchEx) ApplyMapping(ctx context.Context, index string, desired *types.TypeMapping) (*MappingDiff, error) {
+	diff, err := es.DiffMapping(ctx, index, desired)
+	if err != nil {
+		return nil, err
+	}
+	if additive := diff.Additive(); len(additive) > 0 {
+		body, err := json.Marshal(safeMapping(diff.Live, additive))
+		if err != nil {
+			return diff, err
+		}
+		var rsp AcknowledgedResponse
+		if err := doGetResponse[ErrGeneric](ctx, es.Indices.PutMapping(index).Raw(body), &rsp); err != nil {
+			return diff, fmt.Errorf("put mapping of %s failed: %w", index, err)
+		}
+	}
+	if conflicting := diff.Conflicting(); len(conflicting) > 0 {
+		return diff, ErrMappingConflicts{Index: index, Changes: conflicting}
+	}
+	return diff, nil
+}
+
+// diffMapping the live mapping is parsed into types, then both typed mappings are compared parameter by parameter
+// in the same encoding, so e.g. the implicit object type or the JSON type of a value never make a change
+func diffMapping(index string, live map[string]any, desired *types.TypeMapping) (*MappingDiff, error) {
+	liveMapping, err := ParseTypeMapping(live)
+	if err != nil {
+		return nil, fmt.Errorf("live mapping of %s: %w", index, err)
+	}
+	liveParams, err := typedMappingParams(liveMapping)
+	if err != nil {
+		return nil, err
+	}
+	// the bulk load record is not part of the desired state, see WithBulkLoadMode
+	if meta, ok := liveParams["_meta"].(map[string]any); ok {
+		delete(meta, bulkLoadMetaKey)
+		if len(meta) == 0 {
+			delete(liveParams, "_meta")
+		}
+	}
+	desiredParams, err := typedMappingParams(desired)
+	if err != nil {
+		return nil, err
+	}
+	diff := &MappingDiff{Index: index, Live: live, LiveMapping: liveMapping}
+	diff.diffParams("", nil, liveParams, desiredParams)
+	return diff, nil
+}
+
+// typedMappingParams the parameters of the typed mapping by name, nested down to the parameters of every field
+func typedMappingParams(mapping *types.TypeMapping) (map[string]any, error) {
+	params := map[string]any{}
+	if mapping == nil {
+		return params, nil
+	}
+	if err := remarshal(mapping, &params); err != nil {
+		return nil, err
+	}
+	return params, nil
+}
+
+func (d *MappingDiff) add(c MappingChange) {
+	d.Changes = append(d.Changes, c)
+}
+
+func (d *MappingDiff) diffProperties(prefix string, keys []string, live, desired map[string]any) {
+	for _, name := range sortedKeys(desired) {
+		path := joinPath(prefix, name)
+		fieldKeys := appendKeys(keys, name)
+		desiredField, _ := desired[name].(map[string]any)
+		liveField, ok := live[name].(map[string]any)
+		if !ok {
+			d.add(MappingChange{Path: path, Kind: MappingChangeAdditive, Desired: desiredField, Reason: "new field", keys: fieldKeys})
+			continue
+		}
+		liveType, desiredType := fieldType(liveField), fieldType(desiredField)
+		if liveType != desiredType {
+			d.add(MappingChange{Path: path, Param: "type", Kind: MappingChangeConflicting, Live: liveType, Desired: desiredType,
+				Reason: fmt.Sprintf("type %s -> %s, the type of an existing field can not be changed", liveType, desiredType), keys: fieldKeys})
+			continue
+		}
+		d.diffParams(path, fieldKeys, liveField, desiredField)
+	}
+	for _, name := range sortedKeys(live) {
+		if _, ok := desired[name]; !ok {
+			d.add(MappingChange{Path: joinPath(prefix, name), Kind: MappingChangeIgnorable, Live: live[name],
+				Reason: "only in the live mapping, fields can not be removed", keys: appendKeys(keys, name)})
+		}
+	}
+}
+
+func (d *MappingDiff) diffParams(path string, keys []string, live, desired map[string]any) {
+	for _, param := range sortedKeys(desired) {
+		desiredValue := desired[param]
+		switch param {
+		case "type":
+			continue
+		case "properties", "fields":
+			liveProps, _ := live[param].(map[string]any)
+			desiredProps, _ := desired[param].(map[string]any)
+			d.diffProperties(path, appendKeys(keys, param), liveProps, desiredProps)
+			continue
+		}
+		paramKeys := appendKeys(keys, param)
+		liveValue, ok := live[param]
+		switch {
+		case ok && mappingValueEqual(liveValue, desiredValue):
+		case !ok && mappingValueEqual(defaultMappingParams[param], desiredValue):
+			d.add(MappingChange{Path: path, Param: param, Kind: MappingChangeIgnorable, Desired: desiredValue,
+				Reason: fmt.Sprintf("%v is the default", desiredValue), keys: paramKeys})
+		case updatableMappingParams[param]:
+			d.add(MappingChange{Path: path, Param: param, Kind: MappingChangeAdditive, Live: liveValue, Desired: desiredValue,
+				Reason: fmt.Sprintf("%v -> %v, updatable", displayValue(liveValue), desiredValue), keys: paramKeys})
+		default:
+			d.add(MappingChange{Path: path, Param: param, Kind: MappingChangeConflicting, Live: liveValue, Desired: desiredValue,
+				Reason: fmt.Sprintf("%v -> %v, can not be changed on an existing field", displayValue(liveValue), desiredValue), keys: paramKeys})
+		}
+	}
+	for _, param := range sortedKeys(live) {
+		if _, ok := desired[param]; ok || param == "type" || param == "properties" || param == "fields" {
+			continue
+		}
+		c := MappingChange{Path: path, Param: param, Live: live[param], keys: appendKeys(keys, param)}
+		if updatableMappingParams[param] {
+			c.Kind = MappingChangeIgnorable
+			c.Reason = fmt.Sprintf("%v only in the live mapping, kept as is", live[param])
+		} else {
+			c.Kind = MappingChangeConflicting
+			c.Reason = fmt.Sprintf("%v only in the live mapping, can not be reset on an existing field", live[param])
+		}
+		d.add(c)
+	}
+}
+
+// safeMapping the live mapping with only the additive changes applied, which is accepted by the put mapping API
+func safeMapping(live map[string]any, additive []MappingChange) map[string]any {
+	safe := deepCopyMap(live)
+	for _, c := range additive {
+		parent := safe
+		for _, key := range c.keys[:len(c.keys)-1] {
+			next, ok := parent[key].(map[string]any)
+			if !ok {
+				next = map[string]any{}
+				parent[key] = next
+			}
+			parent = next
+		}
+		parent[c.keys[len(c.keys)-1]] = c.Desired
+	}
+	// put mapping replaces the _meta as a whole, the bulk load record must survive it, see WithBulkLoadMode
+	if record, ok := nestedValue(live, "_meta", bulkLoadMetaKey); ok {
+		// the desired _meta is shared with the diff, so it is copied
+		meta, _ := safe["_meta"].(map[string]any)
+		meta = deepCopyMap(meta)
+		meta[bulkLoadMetaKey] = record
+		safe["_meta"] = meta
+	}
+	return safe
+}
+
+func nestedValue(m map[string]any, keys ...string) (any, bool) {
+	var v any = m
+	for _, key := range keys {
+		parent, ok := v.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if v, ok = parent[key]; !ok {
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+func deepCopyMap(m map[string]any) map[string]any {
+	cp := make(map[string]any, len(m))
+	for k, v := range m {
+		if sub, ok := v.(map[string]any); ok {
+			v = deepCopyMap(sub)
+		}
+		cp[k] = v
+	}
+	return cp
+}
+
+// fieldType the object type is implicit in a mapping which is not typed
+func fieldType(field map[string]any) string {
+	if t, ok := field["type"].(string); ok {
+		return t
+	}
+	return "object"
+}
+
+// mappingValueEqual es may return the values in a different JSON type, e.g. "false" for false
+func mappingValueEqual(a, b any) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return reflect.DeepEqual(a, b) || fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+func displayValue(v any) any {
+	if v == nil {
+		return "<unset>"
+	}
+	return v
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func joinPath(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+func appendKeys(keys []string, key string) []string {
+	return append(append(make([]string, 0, len(keys)+1), keys...), key)
+}
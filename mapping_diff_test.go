@@ -0,0 +1,196 @@
+package elastic_wrapper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/dynamicmapping"
+)
+
+type mappingDiffDoc struct {
+	Title   string    `json:"title" es:"text,analyzer=english,search_analyzer=simple,keyword"`
+	Status  string    `json:"status" es:"keyword,ignore_above=64"`
+	Views   int64     `json:"views"`
+	Created time.Time `json:"created"`
+	Author  struct {
+		Name  string `json:"name"`
+		Email string `json:"email"`
+	} `json:"author"`
+	Tags []string `json:"tags" es:"keyword,index=true"`
+}
+
+func TestDiffMapping(t *testing.T) {
+	live := map[string]any{}
+	err := json.Unmarshal([]byte(`{
+	"properties": {
+		"title": {"type": "text", "analyzer": "standard"},
+		"status": {"type": "keyword"},
+		"views": {"type": "integer"},
+		"created": {"type": "date"},
+		"author": {"properties": {"name": {"type": "keyword"}}},
+		"tags": {"type": "keyword"},
+		"legacy": {"type": "keyword"}
+	}
+}`), &live)
+	if err != nil {
+		t.Fatal(err)
+	}
+	desired, err := MappingFor[mappingDiffDoc]()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diff, err := diffMapping("test", live, desired)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log(diff.String())
+
+	expect := map[string]MappingChangeKind{
+		"author.email":          MappingChangeAdditive,
+		"created format":        MappingChangeIgnorable,
+		"legacy":                MappingChangeIgnorable,
+		"status ignore_above":   MappingChangeAdditive,
+		"tags index":            MappingChangeIgnorable,
+		"title analyzer":        MappingChangeConflicting,
+		"title search_analyzer": MappingChangeAdditive,
+		"title.keyword":         MappingChangeAdditive,
+		"views type":            MappingChangeConflicting,
+	}
+	got := make(map[string]MappingChangeKind)
+	for _, c := range diff.Changes {
+		key := c.Path
+		if c.Param != "" {
+			key += " " + c.Param
+		}
+		got[key] = c.Kind
+	}
+	if len(got) != len(expect) {
+		t.Errorf("expect %d changes, got %d: %v", len(expect), len(got), got)
+	}
+	for key, kind := range expect {
+		if got[key] != kind {
+			t.Errorf("change %s: expect %s, got %s", key, kind, got[key])
+		}
+	}
+	if !diff.HasConflicts() {
+		t.Errorf("expect conflicts")
+	}
+
+	safe := safeMapping(diff.Live, diff.Additive())
+	properties := safe["properties"].(map[string]any)
+	title := properties["title"].(map[string]any)
+	if title["analyzer"] != "standard" || title["search_analyzer"] != "simple" {
+		t.Errorf("safe mapping should only apply additive changes, title=%v", title)
+	}
+	if _, ok := title["fields"].(map[string]any)["keyword"]; !ok {
+		t.Errorf("safe mapping should add the multi-field, title=%v", title)
+	}
+	if _, ok := properties["author"].(map[string]any)["properties"].(map[string]any)["email"]; !ok {
+		t.Errorf("safe mapping should add the nested field, author=%v", properties["author"])
+	}
+	if live["properties"].(map[string]any)["title"].(map[string]any)["search_analyzer"] != nil {
+		t.Errorf("the live mapping should not be modified")
+	}
+}
+
+func TestApplyMapping(t *testing.T) {
+	es := newClient(t)
+
+	demoIndex := "test_apply_mapping"
+
+	t.Cleanup(func() {
+		deleted, err := es.IndexDelete(context.Background(), demoIndex)
+		if err != nil {
+			t.Fatalf("delete index failed, err=%v", err)
+		}
+		if !deleted {
+			t.Fatalf("delete index failed, deleted=%v", deleted)
+		}
+	})
+
+	type v1 struct {
+		Title string `json:"title" es:"text"`
+		Views int64  `json:"views"`
+	}
+	if _, err := IndexCreateFor[v1](context.Background(), es, demoIndex); err != nil {
+		t.Fatalf("create index failed, err=%v", err)
+	}
+
+	type v2 struct {
+		Title string `json:"title" es:"text,keyword"`
+		Views int32  `json:"views"`
+		Tags  string `json:"tags"`
+	}
+	desired, err := MappingFor[v2]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff, err := es.ApplyMapping(context.Background(), demoIndex, desired)
+	var conflicts ErrMappingConflicts
+	if !errors.As(err, &conflicts) {
+		t.Fatalf("expect ErrMappingConflicts, err=%v", err)
+	}
+	t.Logf("err=%v", err)
+	t.Log(diff.String())
+
+	// the additive changes are applied, only the conflict is left
+	diff, err = es.DiffMapping(context.Background(), demoIndex, desired)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff.Additive()) != 0 || len(diff.Conflicting()) != 1 {
+		t.Fatalf("unexpected diff after apply: %s", diff)
+	}
+}
+
+func TestSafeMappingKeepsBulkLoadRecord(t *testing.T) {
+	record := map[string]any{"index.refresh_interval": "5s"}
+	live := map[string]any{"_meta": map[string]any{"owner": "search", bulkLoadMetaKey: record}}
+	additive := []MappingChange{{Param: "_meta", Kind: MappingChangeAdditive, Desired: map[string]any{"owner": "feed"}, keys: []string{"_meta"}}}
+
+	safe := safeMapping(live, additive)
+	meta := safe["_meta"].(map[string]any)
+	if meta["owner"] != "feed" || meta[bulkLoadMetaKey] == nil {
+		t.Fatalf("expect the desired _meta with the bulk load record, got %v", meta)
+	}
+	if _, ok := additive[0].Desired.(map[string]any)[bulkLoadMetaKey]; ok {
+		t.Fatalf("the desired _meta of the change should not be modified")
+	}
+}
+
+func TestDiffMappingTyped(t *testing.T) {
+	// the live encoding differs from the typed one: a boolean dynamic, an implicit object type,
+	// and the bulk load record in _meta
+	live := map[string]any{}
+	err := json.Unmarshal([]byte(`{
+	"dynamic": false,
+	"_meta": {"elastic_wrapper_bulk_load": {"index.refresh_interval": ""}},
+	"properties": {"author": {"properties": {"name": {"type": "keyword"}}}}
+}`), &live)
+	if err != nil {
+		t.Fatal(err)
+	}
+	type doc struct {
+		Author struct {
+			Name string `json:"name" es:"keyword"`
+		} `json:"author"`
+	}
+	desired, err := MappingFor[doc](WithMappingDynamic(dynamicmapping.False))
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff, err := diffMapping("test", live, desired)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff.Changes) != 0 {
+		t.Errorf("expect no change, got %s", diff)
+	}
+	if diff.LiveMapping == nil || diff.LiveMapping.Properties["author"] == nil {
+		t.Errorf("expect the typed live mapping, got %+v", diff.LiveMapping)
+	}
+}
@@ -0,0 +1,209 @@
+package elastic_wrapper
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+)
+
+// the types which are only decoded from live mappings, not generated by MappingFor from the es tags,
+// e.g. alias and join need parameters a tag cannot express
+var decodeOnlyProperties = map[string]func() types.Property{
+	"match_only_text":         func() types.Property { return types.NewMatchOnlyTextProperty() },
+	"search_as_you_type":      func() types.Property { return types.NewSearchAsYouTypeProperty() },
+	"completion":              func() types.Property { return types.NewCompletionProperty() },
+	"constant_keyword":        func() types.Property { return types.NewConstantKeywordProperty() },
+	"token_count":             func() types.Property { return types.NewTokenCountProperty() },
+	"version":                 func() types.Property { return types.NewVersionProperty() },
+	"murmur3":                 func() types.Property { return types.NewMurmur3HashProperty() },
+	"integer_range":           func() types.Property { return types.NewIntegerRangeProperty() },
+	"long_range":              func() types.Property { return types.NewLongRangeProperty() },
+	"float_range":             func() types.Property { return types.NewFloatRangeProperty() },
+	"double_range":            func() types.Property { return types.NewDoubleRangeProperty() },
+	"date_range":              func() types.Property { return types.NewDateRangeProperty() },
+	"ip_range":                func() types.Property { return types.NewIpRangeProperty() },
+	"geo_shape":               func() types.Property { return types.NewGeoShapeProperty() },
+	"point":                   func() types.Property { return types.NewPointProperty() },
+	"shape":                   func() types.Property { return types.NewShapeProperty() },
+	"dense_vector":            func() types.Property { return types.NewDenseVectorProperty() },
+	"rank_feature":            func() types.Property { return types.NewRankFeatureProperty() },
+	"rank_features":           func() types.Property { return types.NewRankFeaturesProperty() },
+	"histogram":               func() types.Property { return types.NewHistogramProperty() },
+	"aggregate_metric_double": func() types.Property { return types.NewAggregateMetricDoubleProperty() },
+	"alias":                   func() types.Property { return types.NewFieldAliasProperty() },
+	"join":                    func() types.Property { return types.NewJoinProperty() },
+	"percolator":              func() types.Property { return types.NewPercolatorProperty() },
+}
+
+// ParseTypeMapping decode a mapping as returned by es, go-elasticsearch cannot unmarshal types.TypeMapping
+// as types.Property is an interface, so the properties are decoded by their `type`
+func ParseTypeMapping(raw map[string]any) (*types.TypeMapping, error) {
+	rest := make(map[string]any, len(raw))
+	for k, v := range raw {
+		if k != "properties" && k != "dynamic_templates" {
+			rest[k] = v
+		}
+	}
+	normalizeDynamic(rest)
+	mapping := types.NewTypeMapping()
+	if err := remarshal(rest, mapping); err != nil {
+		return nil, fmt.Errorf("mapping: %w", err)
+	}
+	if properties, ok := raw["properties"]; ok {
+		parsed, err := parseProperties(properties, "")
+		if err != nil {
+			return nil, err
+		}
+		mapping.Properties = parsed
+	}
+	if templates, ok := raw["dynamic_templates"].([]any); ok {
+		parsed, err := parseDynamicTemplates(templates)
+		if err != nil {
+			return nil, err
+		}
+		mapping.DynamicTemplates = parsed
+	}
+	return mapping, nil
+}
+
+func parseProperties(raw any, path string) (map[string]types.Property, error) {
+	fields, ok := raw.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("field %s: properties must be an object, got %T", path, raw)
+	}
+	properties := make(map[string]types.Property, len(fields))
+	for name, field := range fields {
+		fieldPath := joinPath(path, name)
+		m, ok := field.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("field %s: must be an object, got %T", fieldPath, field)
+		}
+		property, err := parseProperty(m, fieldPath)
+		if err != nil {
+			return nil, err
+		}
+		properties[name] = property
+	}
+	return properties, nil
+}
+
+// parseProperty the concrete property of the type, an object field has no type
+func parseProperty(raw map[string]any, path string) (types.Property, error) {
+	typ, _ := raw["type"].(string)
+	if typ == "" {
+		typ = "object"
+	}
+	if newProperty := lookupProperty(typ); newProperty != nil {
+		return decodeProperty(raw, path, typ, newProperty())
+	}
+	// a type the client does not know, e.g. sparse_vector or a plugin type, is kept as a dynamic property
+	dynamic := types.NewDynamicProperty()
+	property, err := decodeProperty(raw, path, typ, dynamic)
+	dynamic.Type = typ
+	return property, err
+}
+
+func lookupProperty(typ string) func() types.Property {
+	if newProperty, ok := mappingProperties[typ]; ok {
+		return newProperty
+	}
+	return decodeOnlyProperties[typ]
+}
+
+func decodeProperty(raw map[string]any, path, typ string, property types.Property) (types.Property, error) {
+	rest := make(map[string]any, len(raw))
+	for k, v := range raw {
+		if k != "properties" && k != "fields" {
+			rest[k] = v
+		}
+	}
+	normalizeDynamic(rest)
+	if err := remarshal(rest, property); err != nil {
+		return nil, fmt.Errorf("field %s: %w", path, err)
+	}
+
+	v := reflect.ValueOf(property).Elem()
+	for key, name := range map[string]string{"properties": "Properties", "fields": "Fields"} {
+		sub, ok := raw[key]
+		if !ok {
+			continue
+		}
+		children, err := parseProperties(sub, path)
+		if err != nil {
+			return nil, err
+		}
+		fv := v.FieldByName(name)
+		if !fv.IsValid() {
+			return nil, fmt.Errorf("field %s: type %s does not support %s", path, typ, key)
+		}
+		fv.Set(reflect.ValueOf(children))
+	}
+	return property, nil
+}
+
+func parseDynamicTemplates(raw []any) ([]map[string]types.DynamicTemplate, error) {
+	templates := make([]map[string]types.DynamicTemplate, 0, len(raw))
+	for _, item := range raw {
+		named, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("dynamic template must be an object, got %T", item)
+		}
+		parsed := make(map[string]types.DynamicTemplate, len(named))
+		for name, tmpl := range named {
+			m, ok := tmpl.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("dynamic template %s: must be an object, got %T", name, tmpl)
+			}
+			rest := make(map[string]any, len(m))
+			for k, v := range m {
+				if k != "mapping" {
+					rest[k] = v
+				}
+			}
+			var dt types.DynamicTemplate
+			if err := remarshal(rest, &dt); err != nil {
+				return nil, fmt.Errorf("dynamic template %s: %w", name, err)
+			}
+			if mapping, ok := m["mapping"].(map[string]any); ok {
+				path := "dynamic_templates." + name
+				var property types.Property
+				var err error
+				// the mapping of a dynamic template may omit the type or use {dynamic_type},
+				// which is then taken from the matched value
+				typ, _ := mapping["type"].(string)
+				if newProperty := lookupProperty(typ); newProperty != nil {
+					property, err = decodeProperty(mapping, path, typ, newProperty())
+				} else {
+					dynamic := types.NewDynamicProperty()
+					property, err = decodeProperty(mapping, path, typ, dynamic)
+					dynamic.Type = typ
+				}
+				if err != nil {
+					return nil, err
+				}
+				dt.Mapping = &property
+			}
+			parsed[name] = dt
+		}
+		templates = append(templates, parsed)
+	}
+	return templates, nil
+}
+
+// normalizeDynamic es may return `dynamic` as a boolean, while dynamicmapping.DynamicMapping only decodes strings
+func normalizeDynamic(m map[string]any) {
+	if b, ok := m["dynamic"].(bool); ok {
+		m["dynamic"] = strconv.FormatBool(b)
+	}
+}
+
+func remarshal(from, to any) error {
+	b, err := json.Marshal(from)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, to)
+}
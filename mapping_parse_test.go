@@ -0,0 +1,89 @@
+package elastic_wrapper
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+)
+
+func TestParseTypeMapping(t *testing.T) {
+	live := `{
+	"dynamic": "strict",
+	"_source": {"excludes": ["secret"]},
+	"dynamic_templates": [
+		{"strings": {"match_mapping_type": "string", "mapping": {"type": "keyword"}}},
+		{"any": {"match": "x_*", "mapping": {"type": "{dynamic_type}", "index": false}}}
+	],
+	"properties": {
+		"title": {"type": "text", "analyzer": "english", "fields": {"keyword": {"type": "keyword", "ignore_above": 256}}},
+		"views": {"type": "long"},
+		"price": {"type": "scaled_float", "scaling_factor": 100.0},
+		"author": {"properties": {"name": {"type": "keyword"}}},
+		"comments": {"type": "nested", "dynamic": true, "properties": {"body": {"type": "text"}}}
+	}
+}`
+	var raw map[string]any
+	if err := json.Unmarshal([]byte(live), &raw); err != nil {
+		t.Fatal(err)
+	}
+	mapping, err := ParseTypeMapping(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	title, ok := mapping.Properties["title"].(*types.TextProperty)
+	if !ok || title.Analyzer == nil || *title.Analyzer != "english" {
+		t.Fatalf("unexpected title %#v", mapping.Properties["title"])
+	}
+	if keyword, ok := title.Fields["keyword"].(*types.KeywordProperty); !ok || *keyword.IgnoreAbove != 256 {
+		t.Fatalf("unexpected title.keyword %#v", title.Fields["keyword"])
+	}
+	if _, ok := mapping.Properties["views"].(*types.LongNumberProperty); !ok {
+		t.Fatalf("unexpected views %#v", mapping.Properties["views"])
+	}
+	author, ok := mapping.Properties["author"].(*types.ObjectProperty)
+	if !ok {
+		t.Fatalf("unexpected author %#v", mapping.Properties["author"])
+	}
+	if _, ok := author.Properties["name"].(*types.KeywordProperty); !ok {
+		t.Fatalf("unexpected author.name %#v", author.Properties["name"])
+	}
+	comments, ok := mapping.Properties["comments"].(*types.NestedProperty)
+	if !ok || comments.Dynamic == nil || comments.Dynamic.String() != "true" {
+		t.Fatalf("unexpected comments %#v", mapping.Properties["comments"])
+	}
+	if mapping.Dynamic == nil || mapping.Dynamic.String() != "strict" || len(mapping.DynamicTemplates) != 2 {
+		t.Fatalf("unexpected mapping %#v", mapping)
+	}
+
+	// the parsed mapping encodes back to the same JSON
+	encoded, err := json.Marshal(mapping)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var roundTrip map[string]any
+	if err := json.Unmarshal(encoded, &roundTrip); err != nil {
+		t.Fatal(err)
+	}
+	// the only differences: dynamic is a string, and an object field gets its explicit type
+	raw["properties"].(map[string]any)["comments"].(map[string]any)["dynamic"] = "true"
+	raw["properties"].(map[string]any)["author"].(map[string]any)["type"] = "object"
+	expect, _ := json.Marshal(raw)
+	got, _ := json.Marshal(roundTrip)
+	if string(expect) != string(got) {
+		t.Errorf("round trip mismatch\nexpect %s\ngot    %s", expect, got)
+	}
+
+	// a type the client does not know is kept as a dynamic property
+	unknown, err := ParseTypeMapping(map[string]any{"properties": map[string]any{
+		"x": map[string]any{"type": "sparse_vector", "fields": map[string]any{"raw": map[string]any{"type": "keyword"}}},
+	}})
+	if err != nil {
+		t.Fatalf("parse unknown type failed, err=%v", err)
+	}
+	x, ok := unknown.Properties["x"].(*types.DynamicProperty)
+	if !ok || x.Type != "sparse_vector" || x.Fields["raw"] == nil {
+		t.Errorf("expect a dynamic property, got %#v", unknown.Properties["x"])
+	}
+}
@@ -0,0 +1,148 @@
+package elastic_wrapper
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/dynamicmapping"
+)
+
+type mappingAuthor struct {
+	Name  string `json:"name"`
+	Email string `json:"email" es:"keyword,index=false"`
+}
+
+type mappingBase struct {
+	ID      string    `json:"id"`
+	Created time.Time `json:"created"`
+}
+
+type mappingArticle struct {
+	mappingBase
+	Title    string            `json:"title" es:"text,analyzer=standard,search_analyzer=simple,keyword"`
+	Tags     []string          `json:"tags"`
+	Views    int64             `json:"views"`
+	Stock    int32             `json:"stock"`
+	Level    int8              `json:"level"`
+	Hits     uint64            `json:"hits"`
+	Price    float64           `json:"price" es:"scaled_float,scaling_factor=100"`
+	Score    *float32          `json:"score,omitempty"`
+	Visible  bool              `json:"visible"`
+	Updated  time.Time         `json:"updated" es:",format=epoch_millis"`
+	Author   mappingAuthor     `json:"author"`
+	Comments []mappingAuthor   `json:"comments" es:"nested,dynamic=strict"`
+	Labels   map[string]string `json:"labels" es:"flattened"`
+	Raw      []byte            `json:"raw" es:",doc_values=false"`
+	Ignored  string            `json:"-"`
+	Skipped  string            `json:"skipped" es:"-"`
+	internal string
+}
+
+func TestMappingFor(t *testing.T) {
+	mapping, err := MappingFor[mappingArticle](WithMappingDynamic(dynamicmapping.Strict))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := json.Marshal(mapping)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var gotMap, expectMap map[string]any
+	if err := json.Unmarshal(got, &gotMap); err != nil {
+		t.Fatal(err)
+	}
+
+	expect := `{
+	"dynamic": "strict",
+	"properties": {
+		"id": {"type": "keyword"},
+		"created": {"type": "date", "format": "strict_date_optional_time||epoch_millis"},
+		"title": {"type": "text", "analyzer": "standard", "search_analyzer": "simple",
+			"fields": {"keyword": {"type": "keyword", "ignore_above": 256}}},
+		"tags": {"type": "keyword"},
+		"views": {"type": "long"},
+		"stock": {"type": "integer"},
+		"level": {"type": "byte"},
+		"hits": {"type": "unsigned_long"},
+		"price": {"type": "scaled_float", "scaling_factor": 100},
+		"score": {"type": "float"},
+		"visible": {"type": "boolean"},
+		"updated": {"type": "date", "format": "epoch_millis"},
+		"author": {"type": "object", "properties": {
+			"name": {"type": "keyword"},
+			"email": {"type": "keyword", "index": false}
+		}},
+		"comments": {"type": "nested", "dynamic": "strict", "properties": {
+			"name": {"type": "keyword"},
+			"email": {"type": "keyword", "index": false}
+		}},
+		"labels": {"type": "flattened"},
+		"raw": {"type": "binary", "doc_values": false}
+	}
+}`
+	if err := json.Unmarshal([]byte(expect), &expectMap); err != nil {
+		t.Fatal(err)
+	}
+	expectJSON, _ := json.Marshal(expectMap)
+	gotJSON, _ := json.Marshal(gotMap)
+	if string(expectJSON) != string(gotJSON) {
+		t.Fatalf("mapping mismatch\nexpect: %s\ngot:    %s", expectJSON, gotJSON)
+	}
+}
+
+func TestMappingForErrors(t *testing.T) {
+	type unknownOption struct {
+		Name string `json:"name" es:"keyword,analyzer=standard"`
+	}
+	if _, err := MappingFor[unknownOption](); err == nil {
+		t.Errorf("analyzer should not be supported by keyword")
+	}
+
+	type missingScalingFactor struct {
+		Price float64 `json:"price" es:"scaled_float"`
+	}
+	if _, err := MappingFor[missingScalingFactor](); err == nil {
+		t.Errorf("scaled_float without scaling_factor should fail")
+	}
+
+	type cannotInfer struct {
+		Any any `json:"any"`
+	}
+	if _, err := MappingFor[cannotInfer](); err == nil {
+		t.Errorf("interface field without es type should fail")
+	}
+
+	type node struct {
+		Children []node `json:"children"`
+	}
+	if _, err := MappingFor[node](); err == nil {
+		t.Errorf("recursive type should fail")
+	}
+
+	if _, err := MappingFor[string](); err == nil {
+		t.Errorf("non struct type should fail")
+	}
+}
+
+func TestIndexCreateFor(t *testing.T) {
+	es := newClient(t)
+
+	demoIndex := "test_index_create_for"
+
+	t.Cleanup(func() {
+		deleted, err := es.IndexDelete(context.Background(), demoIndex)
+		if err != nil {
+			t.Fatalf("delete index failed, err=%v", err)
+		}
+		if !deleted {
+			t.Fatalf("delete index failed, deleted=%v", deleted)
+		}
+	})
+	rsp, err := IndexCreateFor[mappingArticle](context.Background(), es, demoIndex, WithMappingDynamic(dynamicmapping.Strict))
+	if err != nil {
+		t.Fatalf("create index failed, err=%v", err)
+	}
+	t.Logf("rsp=%+v", rsp)
+}
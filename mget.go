@@ -0,0 +1,149 @@
+package elastic_wrapper
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/core/mget"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+)
+
+// MGetItem one document to get, Index is required, the other fields are optional
+type MGetItem struct {
+	Index          string
+	ID             string
+	Routing        string
+	SourceIncludes []string
+	SourceExcludes []string
+	NoSource       bool // do not return the _source
+}
+
+type MGetStatus string
+
+const (
+	MGetFound   MGetStatus = "found"
+	MGetMissing MGetStatus = "missing"
+	MGetError   MGetStatus = "error"
+)
+
+// MGetResult the result of one MGetItem
+type MGetResult[T any] struct {
+	Index       string      `json:"_index"`
+	Id          string      `json:"_id"`
+	Version     int         `json:"_version"`
+	SeqNo       int         `json:"_seq_no"`
+	PrimaryTerm int         `json:"_primary_term"`
+	Routing     string      `json:"_routing"`
+	Found       bool        `json:"found"`
+	Source      T           `json:"_source"`
+	Error       *ErrorCause `json:"error"`
+}
+
+func (r *MGetResult[T]) Status() MGetStatus {
+	if r.Error != nil {
+		return MGetError
+	}
+	if r.Found {
+		return MGetFound
+	}
+	return MGetMissing
+}
+
+// MGetResponse Docs are in the same order as the request items
+type MGetResponse[T any] struct {
+	Docs []MGetResult[T] `json:"docs"`
+}
+
+func (m *MGetResponse[T]) FromJSON(i []byte) error {
+	return FromJSONImplDefault(i, m)
+}
+
+// Sources the found documents in request order
+func (m *MGetResponse[T]) Sources() []T {
+	docs := make([]T, 0, len(m.Docs))
+	for idx := range m.Docs {
+		if m.Docs[idx].Found {
+			docs = append(docs, m.Docs[idx].Source)
+		}
+	}
+	return docs
+}
+
+// ByID the found documents by id, the later one wins if the same id is found in multiple indices
+func (m *MGetResponse[T]) ByID() map[string]T {
+	docs := make(map[string]T, len(m.Docs))
+	for idx := range m.Docs {
+		if m.Docs[idx].Found {
+			docs[m.Docs[idx].Id] = m.Docs[idx].Source
+		}
+	}
+	return docs
+}
+
+// Errors the items failed to get, a missing document is not an error
+func (m *MGetResponse[T]) Errors() []MGetResult[T] {
+	var docs []MGetResult[T]
+	for idx := range m.Docs {
+		if m.Docs[idx].Error != nil {
+			docs = append(docs, m.Docs[idx])
+		}
+	}
+	return docs
+}
+
+func mgetRequest(items []MGetItem) (*mget.Request, error) {
+	req := mget.NewRequest()
+	req.Docs = make([]types.MgetOperation, 0, len(items))
+	for _, item := range items {
+		if item.Index == "" || item.ID == "" {
+			return nil, fmt.Errorf("mget item index and id are required, index=%s id=%s", item.Index, item.ID)
+		}
+		index := item.Index
+		op := types.MgetOperation{
+			Id_:    item.ID,
+			Index_: &index,
+		}
+		if item.Routing != "" {
+			routing := item.Routing
+			op.Routing = &routing
+		}
+		if item.NoSource {
+			var source types.SourceConfig = false
+			op.Source_ = &source
+		} else if len(item.SourceIncludes) > 0 || len(item.SourceExcludes) > 0 {
+			var source types.SourceConfig = types.SourceFilter{
+				Includes: item.SourceIncludes,
+				Excludes: item.SourceExcludes,
+			}
+			op.Source_ = &source
+		}
+		req.Docs = append(req.Docs, op)
+	}
+	return req, nil
+}
+
+// MGet get multiple documents across indices, results are in request order with found/missing/error status per item
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/docs-multi-get.html
+// supported options: WithPreference, WithRealtime, WithRefreshBeforeGet,
+// WithRouting is the default routing of the items without their own Routing
+func MGet[T any](ctx context.Context, es *ElasticsearchEx, items []MGetItem, opts ...RequestOption) (*MGetResponse[T], error) {
+	request, err := mgetRequest(items)
+	if err != nil {
+		return nil, err
+	}
+	o := newRequestOptions(opts)
+	req := applyRouting(es.Core.Mget().Request(request), o)
+	if o.preference != "" {
+		req.Preference(o.preference)
+	}
+	if o.realtime != nil {
+		req.Realtime(*o.realtime)
+	}
+	if o.refresh != nil {
+		req.Refresh(*o.refresh)
+	}
+
+	var rsp MGetResponse[T]
+	err = doGetResponse[ErrGeneric](ctx, req, &rsp)
+	return &rsp, err
+}
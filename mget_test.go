@@ -0,0 +1,77 @@
+package elastic_wrapper
+
+import (
+	"context"
+	"testing"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/refresh"
+)
+
+func TestMGetTyped(t *testing.T) {
+	es := newClient(t)
+	ctx := context.Background()
+
+	indexA := "test_doc_mget_typed_a"
+	indexB := "test_doc_mget_typed_b"
+
+	t.Cleanup(func() {
+		for _, index := range []string{indexA, indexB} {
+			deleted, err := es.IndexDelete(context.Background(), index)
+			if err != nil {
+				t.Fatalf("delete index failed, err=%v", err)
+			}
+			if !deleted {
+				t.Fatalf("delete index failed, deleted=%v", deleted)
+			}
+		}
+	})
+
+	type DemoDoc struct {
+		Title   string `json:"title"`
+		Content string `json:"content"`
+	}
+
+	for _, index := range []string{indexA, indexB} {
+		_, err := es.IndexCreateSimple(ctx, index, &types.TypeMapping{Properties: map[string]types.Property{
+			"title":   types.NewKeywordProperty(),
+			"content": types.NewKeywordProperty(),
+		}})
+		if err != nil {
+			t.Fatalf("create simple index failed, err=%v", err)
+		}
+		_, err = es.DocCreateRefresh(ctx, index, index+"_1", &DemoDoc{Title: index, Content: "hello"}, refresh.True)
+		if err != nil {
+			t.Fatalf("create doc failed, err=%v", err)
+		}
+	}
+
+	rsp, err := MGet[DemoDoc](ctx, es, []MGetItem{
+		{Index: indexB, ID: indexB + "_1", SourceIncludes: []string{"title"}},
+		{Index: indexA, ID: "missing"},
+		{Index: indexA, ID: indexA + "_1"},
+		{Index: "test_doc_mget_typed_no_such_index", ID: "1"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("rsp=%+v", rsp)
+
+	expected := []MGetStatus{MGetFound, MGetMissing, MGetFound, MGetError}
+	if len(rsp.Docs) != len(expected) {
+		t.Fatalf("len(rsp.Docs)=%d", len(rsp.Docs))
+	}
+	for idx := range rsp.Docs {
+		if status := rsp.Docs[idx].Status(); status != expected[idx] {
+			t.Errorf("doc %d status=%s, expected %s", idx, status, expected[idx])
+		}
+	}
+	if rsp.Docs[0].Source.Title != indexB || rsp.Docs[0].Source.Content != "" {
+		t.Errorf("source filtering not work, source=%+v", rsp.Docs[0].Source)
+	}
+
+	byID := rsp.ByID()
+	if len(byID) != 2 || byID[indexA+"_1"].Content != "hello" {
+		t.Errorf("byID=%+v", byID)
+	}
+}
@@ -10,3 +10,12 @@ type FromJSON interface {
 func FromJSONImplDefault(data []byte, v any) error {
 	return json.Unmarshal(data, v)
 }
+
+// typedResponse decode the response body directly into v, for endpoints which return the bare document
+type typedResponse[T any] struct {
+	v *T
+}
+
+func (r *typedResponse[T]) FromJSON(buf []byte) error {
+	return FromJSONImplDefault(buf, r.v)
+}
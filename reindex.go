@@ -0,0 +1,129 @@
+package elastic_wrapper
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/core/reindex"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/conflicts"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/optype"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/versiontype"
+)
+
+// ReindexRemote reindex from a remote cluster, the host must be allowed by `reindex.remote.whitelist`
+type ReindexRemote struct {
+	Host           string // e.g. https://otherhost:9200
+	Username       string
+	Password       string
+	Headers        map[string]string
+	ConnectTimeout string // e.g. 30s
+	SocketTimeout  string // e.g. 1m
+}
+
+// ReindexSpec describe a reindex, Source and Dest are required
+type ReindexSpec struct {
+	Source       []string     // source indices
+	Query        *types.Query // only reindex the matched documents
+	Size         int          // the batch size of the source scroll, 1000 by default
+	SourceFields []string     // only copy the listed source fields
+	MaxDocs      int64        // 0 means all
+	Remote       *ReindexRemote
+
+	Dest        string
+	OpType      *optype.OpType           // optype.Create only creates missing documents
+	VersionType *versiontype.VersionType // versiontype.External keeps the source versions and only overwrites older documents
+	Pipeline    string                   // the ingest pipeline of the dest index
+	Script      *types.Script            // painless script to transform documents, `ctx._source` is the document
+
+	Slices            string  // "auto" by default, slicing is not supported with a remote source
+	RequestsPerSecond float64 // 0 means no throttling
+	ConflictsProceed  bool    // count version conflicts instead of aborting
+	Refresh           bool    // refresh the dest index when done
+}
+
+func (spec *ReindexSpec) request() (*reindex.Request, error) {
+	if len(spec.Source) == 0 {
+		return nil, fmt.Errorf("reindex source index is required")
+	}
+	if spec.Dest == "" {
+		return nil, fmt.Errorf("reindex dest index is required")
+	}
+
+	req := reindex.NewRequest()
+	req.Source.Index = spec.Source
+	req.Source.Query = spec.Query
+	req.Source.SourceFields_ = spec.SourceFields
+	if spec.Size > 0 {
+		size := spec.Size
+		req.Source.Size = &size
+	}
+	if spec.MaxDocs > 0 {
+		maxDocs := spec.MaxDocs
+		req.MaxDocs = &maxDocs
+	}
+	if spec.Remote != nil {
+		remote := &types.RemoteSource{
+			Host:    spec.Remote.Host,
+			Headers: spec.Remote.Headers,
+		}
+		if spec.Remote.Username != "" {
+			remote.Username = &spec.Remote.Username
+			remote.Password = &spec.Remote.Password
+		}
+		if spec.Remote.ConnectTimeout != "" {
+			var timeout types.Duration = spec.Remote.ConnectTimeout
+			remote.ConnectTimeout = &timeout
+		}
+		if spec.Remote.SocketTimeout != "" {
+			var timeout types.Duration = spec.Remote.SocketTimeout
+			remote.SocketTimeout = &timeout
+		}
+		req.Source.Remote = remote
+	}
+
+	req.Dest.Index = spec.Dest
+	req.Dest.OpType = spec.OpType
+	req.Dest.VersionType = spec.VersionType
+	if spec.Pipeline != "" {
+		pipeline := spec.Pipeline
+		req.Dest.Pipeline = &pipeline
+	}
+	req.Script = spec.Script
+	if spec.ConflictsProceed {
+		req.Conflicts = &conflicts.Proceed
+	}
+	return req, nil
+}
+
+// Reindex copies documents from the source to the dest index as a background task,
+// track it with Task.WaitProgress, the final summary includes the failures
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/docs-reindex.html
+func (es *ElasticsearchEx) Reindex(ctx context.Context, spec ReindexSpec) (*Task, error) {
+	request, err := spec.request()
+	if err != nil {
+		return nil, err
+	}
+
+	req := es.Core.Reindex().Request(request).WaitForCompletion(false)
+	if spec.Slices != "" {
+		req.Slices(spec.Slices)
+	} else if spec.Remote == nil {
+		req.Slices(defaultSlices)
+	}
+	if spec.RequestsPerSecond > 0 {
+		req.RequestsPerSecond(formatRequestsPerSecond(spec.RequestsPerSecond))
+	}
+	if spec.Refresh {
+		req.Refresh(true)
+	}
+
+	var rsp taskStartResponse
+	if err := doGetResponse[ErrGeneric](ctx, req, &rsp); err != nil {
+		return nil, err
+	}
+	return newTask(es, rsp.Task, func(ctx context.Context, taskID, rps string) error {
+		var rsp ResponseGeneric
+		return doGetResponse[ErrGeneric](ctx, es.Core.ReindexRethrottle(taskID).RequestsPerSecond(rps), &rsp)
+	}), nil
+}
@@ -0,0 +1,119 @@
+package elastic_wrapper
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/refresh"
+)
+
+func TestReindexSpecRequest(t *testing.T) {
+	if _, err := (&ReindexSpec{Dest: "b"}).request(); err == nil {
+		t.Fatalf("reindex without source should fail")
+	}
+	if _, err := (&ReindexSpec{Source: []string{"a"}}).request(); err == nil {
+		t.Fatalf("reindex without dest should fail")
+	}
+
+	spec := ReindexSpec{
+		Source:   []string{"a"},
+		Size:     500,
+		Dest:     "b",
+		Pipeline: "p",
+		Remote: &ReindexRemote{
+			Host:          "https://otherhost:9200",
+			Username:      "user",
+			Password:      "pass",
+			SocketTimeout: "1m",
+		},
+		ConflictsProceed: true,
+	}
+	req, err := spec.request()
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf, err := json.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"conflicts":"proceed","dest":{"index":"b","pipeline":"p"},` +
+		`"source":{"index":["a"],"remote":{"host":"https://otherhost:9200","password":"pass","socket_timeout":"1m","username":"user"},"size":500}}`
+	if string(buf) != expected {
+		t.Fatalf("got %s, expected %s", buf, expected)
+	}
+}
+
+func TestReindex(t *testing.T) {
+	es := newClient(t)
+	ctx := context.Background()
+
+	srcIndex := "test_reindex_src"
+	destIndex := "test_reindex_dest"
+
+	t.Cleanup(func() {
+		for _, index := range []string{srcIndex, destIndex} {
+			deleted, err := es.IndexDelete(context.Background(), index)
+			if err != nil {
+				t.Fatalf("delete index failed, err=%v", err)
+			}
+			if !deleted {
+				t.Fatalf("delete index failed, deleted=%v", deleted)
+			}
+		}
+	})
+
+	for _, index := range []string{srcIndex, destIndex} {
+		_, err := es.IndexCreateSimple(ctx, index, &types.TypeMapping{
+			Properties: map[string]types.Property{
+				"count": types.NewIntegerNumberProperty(),
+			},
+		})
+		if err != nil {
+			t.Fatalf("create simple index failed, err=%v", err)
+		}
+	}
+
+	for i := 0; i < 10; i++ {
+		_, err := es.DocCreateRefresh(ctx, srcIndex, strconv.Itoa(i), map[string]any{"count": i}, refresh.True)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	script := types.NewInlineScript()
+	script.Source = "ctx._source.count *= 10"
+	var theScript types.Script = script
+
+	task, err := es.Reindex(ctx, ReindexSpec{
+		Source:  []string{srcIndex},
+		Size:    3,
+		Dest:    destIndex,
+		Script:  &theScript,
+		Refresh: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsp, err := task.WaitProgress(ctx, func(status *BulkByScrollStatus) {
+		t.Logf("progress=%d/%d batches=%d", status.Done(), status.Total, status.Batches)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("rsp=%+v", rsp)
+	if rsp.Created != 10 {
+		t.Fatalf("rsp.Created=%d", rsp.Created)
+	}
+
+	var docGetRsp DocGetResponse[map[string]int]
+	err = es.DocGet(ctx, destIndex, "3", &docGetRsp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if docGetRsp.Source["count"] != 30 {
+		t.Fatalf("docGetRsp.Source=%+v", docGetRsp.Source)
+	}
+}
@@ -0,0 +1,341 @@
+package elastic_wrapper
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/core/count"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/core/create"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/core/delete"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/core/exists"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/core/get"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/core/getsource"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/core/index"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/core/mget"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/core/mtermvectors"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/core/search"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/core/termvectors"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/core/update"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/versiontype"
+)
+
+// RequestOption tune a single wrapper call, every operation only picks up the options it supports
+type RequestOption func(*requestOptions)
+
+type requestOptions struct {
+	source         *bool
+	sourceIncludes []string
+	sourceExcludes []string
+	storedFields   []string
+	routing        string
+	preference     string
+	realtime       *bool
+	refresh        *bool
+	version        *int64
+	versionType    *versiontype.VersionType
+	pipeline       string
+
+	slices            string
+	requestsPerSecond *float64
+	conflictsProceed  bool
+	maxDocs           *int64
+
+	flatSettings    bool
+	includeDefaults bool
+}
+
+func newRequestOptions(opts []RequestOption) *requestOptions {
+	o := &requestOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithSource enable or disable returning the _source field
+func WithSource(enabled bool) RequestOption {
+	return func(o *requestOptions) {
+		o.source = &enabled
+	}
+}
+
+// WithSourceIncludes only return the listed source fields, wildcards are supported
+func WithSourceIncludes(fields ...string) RequestOption {
+	return func(o *requestOptions) {
+		o.sourceIncludes = append(o.sourceIncludes, fields...)
+	}
+}
+
+// WithSourceExcludes exclude the listed source fields from the response, wildcards are supported
+func WithSourceExcludes(fields ...string) RequestOption {
+	return func(o *requestOptions) {
+		o.sourceExcludes = append(o.sourceExcludes, fields...)
+	}
+}
+
+// WithStoredFields return the listed stored fields (mapping `store: true`) in the response
+func WithStoredFields(fields ...string) RequestOption {
+	return func(o *requestOptions) {
+		o.storedFields = append(o.storedFields, fields...)
+	}
+}
+
+// WithRouting target the primary shard of the custom routing value,
+// a document written with a custom routing must be read, updated and deleted with the same routing
+func WithRouting(routing string) RequestOption {
+	return func(o *requestOptions) {
+		o.routing = routing
+	}
+}
+
+// WithPreference specify the node or shard the operation should be performed on
+func WithPreference(preference string) RequestOption {
+	return func(o *requestOptions) {
+		o.preference = preference
+	}
+}
+
+// WithRealtime realtime get is the default, set false to read from the last refreshed view only
+func WithRealtime(realtime bool) RequestOption {
+	return func(o *requestOptions) {
+		o.realtime = &realtime
+	}
+}
+
+// WithRefreshBeforeGet refresh the relevant shard before the get operation
+func WithRefreshBeforeGet(refresh bool) RequestOption {
+	return func(o *requestOptions) {
+		o.refresh = &refresh
+	}
+}
+
+// WithVersion explicit version number for concurrency control,
+// the request fails with 409 if the version does not match the current version of the document.
+// Writes require WithVersionType too, es rejects internal versioning on writes, they return ErrVersionWithoutType otherwise
+func WithVersion(version int64) RequestOption {
+	return func(o *requestOptions) {
+		o.version = &version
+	}
+}
+
+// WithVersionType set the version type used together with WithVersion
+func WithVersionType(versionType versiontype.VersionType) RequestOption {
+	return func(o *requestOptions) {
+		o.versionType = &versionType
+	}
+}
+
+// WithExternalVersion the write only succeeds if version is greater than the current version of the document,
+// the version is maintained by an external system such as a database row version.
+// A rejected write returns ErrStaleVersion
+func WithExternalVersion(version int64) RequestOption {
+	return func(o *requestOptions) {
+		o.version = &version
+		o.versionType = &versiontype.External
+	}
+}
+
+// WithExternalGteVersion like WithExternalVersion, but the write also succeeds if version equals the current version
+func WithExternalGteVersion(version int64) RequestOption {
+	return func(o *requestOptions) {
+		o.version = &version
+		o.versionType = &versiontype.Externalgte
+	}
+}
+
+// WithPipeline the ingest pipeline to preprocess the written documents
+func WithPipeline(pipeline string) RequestOption {
+	return func(o *requestOptions) {
+		o.pipeline = pipeline
+	}
+}
+
+// WithSlices the number of slices a by query task is divided into, "auto" by default
+func WithSlices(slices string) RequestOption {
+	return func(o *requestOptions) {
+		o.slices = slices
+	}
+}
+
+// WithRequestsPerSecond throttle a by query task, -1 to disable throttling
+func WithRequestsPerSecond(requestsPerSecond float64) RequestOption {
+	return func(o *requestOptions) {
+		o.requestsPerSecond = &requestsPerSecond
+	}
+}
+
+// WithConflictsProceed count version conflicts instead of aborting a by query task
+func WithConflictsProceed() RequestOption {
+	return func(o *requestOptions) {
+		o.conflictsProceed = true
+	}
+}
+
+// WithMaxDocs the maximum number of documents a by query task processes
+func WithMaxDocs(maxDocs int64) RequestOption {
+	return func(o *requestOptions) {
+		o.maxDocs = &maxDocs
+	}
+}
+
+// WithFlatSettings return the index settings with flat keys, e.g. index.number_of_shards
+func WithFlatSettings() RequestOption {
+	return func(o *requestOptions) {
+		o.flatSettings = true
+	}
+}
+
+// WithIncludeDefaults also return the default values of the index settings which are not explicitly set
+func WithIncludeDefaults() RequestOption {
+	return func(o *requestOptions) {
+		o.includeDefaults = true
+	}
+}
+
+// getRequest is the builder set shared by the get, exists and get source API
+type getRequest[R any] interface {
+	Source_(value string) R
+	SourceIncludes_(value string) R
+	SourceExcludes_(value string) R
+	StoredFields(value string) R
+	Routing(value string) R
+	Preference(value string) R
+	Realtime(b bool) R
+	Refresh(b bool) R
+	Version(value string) R
+	VersionType(enum versiontype.VersionType) R
+}
+
+var (
+	_ getRequest[*get.Get]             = (*get.Get)(nil)
+	_ getRequest[*exists.Exists]       = (*exists.Exists)(nil)
+	_ getRequest[*getsource.GetSource] = (*getsource.GetSource)(nil)
+)
+
+func applyGetOptions[R getRequest[R]](req R, o *requestOptions) R {
+	if o.source != nil {
+		req.Source_(strconv.FormatBool(*o.source))
+	}
+	if len(o.sourceIncludes) > 0 {
+		req.SourceIncludes_(strings.Join(o.sourceIncludes, ","))
+	}
+	if len(o.sourceExcludes) > 0 {
+		req.SourceExcludes_(strings.Join(o.sourceExcludes, ","))
+	}
+	if len(o.storedFields) > 0 {
+		req.StoredFields(strings.Join(o.storedFields, ","))
+	}
+	if o.routing != "" {
+		req.Routing(o.routing)
+	}
+	if o.preference != "" {
+		req.Preference(o.preference)
+	}
+	if o.realtime != nil {
+		req.Realtime(*o.realtime)
+	}
+	if o.refresh != nil {
+		req.Refresh(*o.refresh)
+	}
+	if o.version != nil {
+		req.Version(strconv.FormatInt(*o.version, 10))
+	}
+	if o.versionType != nil {
+		req.VersionType(*o.versionType)
+	}
+	return req
+}
+
+// routedRequest is the builder set of every API which accepts custom routing
+type routedRequest[R any] interface {
+	Routing(value string) R
+}
+
+var (
+	_ routedRequest[*update.Update]             = (*update.Update)(nil)
+	_ routedRequest[*search.Search]             = (*search.Search)(nil)
+	_ routedRequest[*count.Count]               = (*count.Count)(nil)
+	_ routedRequest[*mget.Mget]                 = (*mget.Mget)(nil)
+	_ routedRequest[*termvectors.Termvectors]   = (*termvectors.Termvectors)(nil)
+	_ routedRequest[*mtermvectors.Mtermvectors] = (*mtermvectors.Mtermvectors)(nil)
+)
+
+func applyRouting[R routedRequest[R]](req R, o *requestOptions) R {
+	if o.routing != "" {
+		req.Routing(o.routing)
+	}
+	return req
+}
+
+// versionedRequest is the builder set shared by the index and delete API,
+// create only supports internal versioning, see applyCreateOptions
+type versionedRequest[R any] interface {
+	routedRequest[R]
+	Version(value string) R
+	VersionType(enum versiontype.VersionType) R
+}
+
+var (
+	_ versionedRequest[*index.Index]   = (*index.Index)(nil)
+	_ versionedRequest[*delete.Delete] = (*delete.Delete)(nil)
+)
+
+// applyWriteOptions ErrVersionWithoutType if a version is given without a version type
+func applyWriteOptions[R versionedRequest[R]](req R, o *requestOptions) (R, error) {
+	if err := o.checkWriteVersion(); err != nil {
+		return req, err
+	}
+	if o.version != nil {
+		req.Version(strconv.FormatInt(*o.version, 10))
+	}
+	if o.versionType != nil {
+		req.VersionType(*o.versionType)
+	}
+	return applyRouting(req, o), nil
+}
+
+// checkWriteVersion es only supports internal versioning on get, writes need a version type
+func (o *requestOptions) checkWriteVersion() error {
+	if o.version != nil && o.versionType == nil {
+		return ErrVersionWithoutType
+	}
+	return nil
+}
+
+// pipelineRequest is the builder set shared by the index and create API
+type pipelineRequest[R any] interface {
+	Pipeline(value string) R
+}
+
+var (
+	_ pipelineRequest[*index.Index]   = (*index.Index)(nil)
+	_ pipelineRequest[*create.Create] = (*create.Create)(nil)
+)
+
+func applyPipeline[R pipelineRequest[R]](req R, o *requestOptions) R {
+	if o.pipeline != "" {
+		req.Pipeline(o.pipeline)
+	}
+	return req
+}
+
+func applyIndexOptions(req *index.Index, o *requestOptions) (*index.Index, error) {
+	return applyWriteOptions(applyPipeline(req, o), o)
+}
+
+// applyCreateOptions ErrCreateVersioning if a version option is given, es rejects versioning on create
+func applyCreateOptions(req *create.Create, o *requestOptions) (*create.Create, error) {
+	if o.version != nil || o.versionType != nil {
+		return nil, ErrCreateVersioning
+	}
+	return applyRouting(applyPipeline(req, o), o), nil
+}
+
+// versionConflictErr a conflict of a versioned write means the write is stale
+func (o *requestOptions) versionConflictErr(err error) error {
+	if o.version != nil && errors.Is(err, ErrConflict) {
+		return ErrStaleVersion
+	}
+	return err
+}
@@ -0,0 +1,151 @@
+package elastic_wrapper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+)
+
+const defaultAutoRollInterval = time.Minute
+
+// RolloverOptions the optional settings of Rollover
+type RolloverOptions struct {
+	NewIndex string // the name of the new index, the old name with the incremented -000001 suffix by default
+	DryRun   bool   // only check the conditions, nothing is rolled over
+
+	// the settings, mappings and aliases of the new index, on top of the matching index templates.
+	// Not supported by data streams
+	Settings *types.IndexSettings
+	Mappings *types.TypeMapping
+	Aliases  map[string]types.Alias
+}
+
+type rolloverBody struct {
+	Conditions *RolloverConditions    `json:"conditions,omitempty"`
+	Settings   *types.IndexSettings   `json:"settings,omitempty"`
+	Mappings   *types.TypeMapping     `json:"mappings,omitempty"`
+	Aliases    map[string]types.Alias `json:"aliases,omitempty"`
+}
+
+type RolloverResponse struct {
+	Acknowledged       bool            `json:"acknowledged"`
+	ShardsAcknowledged bool            `json:"shards_acknowledged"`
+	OldIndex           string          `json:"old_index"`
+	NewIndex           string          `json:"new_index"`
+	RolledOver         bool            `json:"rolled_over"`
+	DryRun             bool            `json:"dry_run"`
+	Conditions         map[string]bool `json:"conditions"` // condition -> matched, e.g. "[max_age: 7d]": true
+}
+
+func (r *RolloverResponse) FromJSON(bytes []byte) error {
+	return FromJSONImplDefault(bytes, r)
+}
+
+// MatchedConditions the sorted conditions which are met
+func (r *RolloverResponse) MatchedConditions() []string {
+	var matched []string
+	for condition, ok := range r.Conditions {
+		if ok {
+			matched = append(matched, condition)
+		}
+	}
+	sort.Strings(matched)
+	return matched
+}
+
+// Rollover create a new index for the alias or data stream and make it the write index, once any of the max
+// conditions and all the min conditions are met. The rollover is unconditional if conditions is nil, opts is optional
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/indices-rollover-index.html
+func (es *ElasticsearchEx) Rollover(ctx context.Context, alias string, conditions *RolloverConditions, opts *RolloverOptions) (*RolloverResponse, error) {
+	if opts == nil {
+		opts = &RolloverOptions{}
+	}
+	body, err := json.Marshal(rolloverBody{
+		Conditions: conditions,
+		Settings:   opts.Settings,
+		Mappings:   opts.Mappings,
+		Aliases:    opts.Aliases,
+	})
+	if err != nil {
+		return nil, err
+	}
+	req := es.Indices.Rollover(alias).Raw(body)
+	if opts.NewIndex != "" {
+		req.NewIndex(opts.NewIndex)
+	}
+	if opts.DryRun {
+		req.DryRun(true)
+	}
+	var rsp RolloverResponse
+	err = doGetResponse[ErrGeneric](ctx, req, &rsp)
+	return &rsp, err
+}
+
+// AutoRoller check the rollover conditions of an alias periodically, for the setups without ILM
+type AutoRoller struct {
+	Alias      string
+	Conditions RolloverConditions
+	Interval   time.Duration    // 1 minute by default
+	Options    *RolloverOptions // optional, NewIndex must be empty as every rollover needs a new name
+
+	OnRollover func(rsp *RolloverResponse) // called after every actual rollover
+	OnError    func(err error)             // called with the error of a check, Run stops on the first error if nil
+
+	es *ElasticsearchEx
+}
+
+func (es *ElasticsearchEx) NewAutoRoller(alias string, conditions RolloverConditions, interval time.Duration) *AutoRoller {
+	return &AutoRoller{
+		Alias:      alias,
+		Conditions: conditions,
+		Interval:   interval,
+		es:         es,
+	}
+}
+
+// Check roll over once if the conditions are met
+func (r *AutoRoller) Check(ctx context.Context) (*RolloverResponse, error) {
+	rsp, err := r.es.Rollover(ctx, r.Alias, &r.Conditions, r.Options)
+	if err != nil {
+		return rsp, err
+	}
+	if rsp.RolledOver && r.OnRollover != nil {
+		r.OnRollover(rsp)
+	}
+	return rsp, nil
+}
+
+// Run check the conditions every Interval until ctx is done, it blocks and returns ctx.Err() at the end
+func (r *AutoRoller) Run(ctx context.Context) error {
+	if r.Conditions == (RolloverConditions{}) {
+		return errors.New("auto roller without conditions would roll over on every check")
+	}
+	if r.Options != nil && r.Options.NewIndex != "" {
+		return errors.New("auto roller cannot roll over to a fixed NewIndex")
+	}
+	interval := r.Interval
+	if interval <= 0 {
+		interval = defaultAutoRollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.Check(ctx); err != nil {
+			if r.OnError == nil {
+				return err
+			}
+			r.OnError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
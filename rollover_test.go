@@ -0,0 +1,98 @@
+package elastic_wrapper
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/indices/create"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/refresh"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestRollover(t *testing.T) {
+	es := newClient(t)
+
+	alias := "test_rollover"
+	firstIndex := "test_rollover-000001"
+	secondIndex := "test_rollover-000002"
+	thirdIndex := "test_rollover-000003"
+
+	t.Cleanup(func() {
+		for _, index := range []string{firstIndex, secondIndex, thirdIndex} {
+			deleted, err := es.IndexDelete(context.Background(), index)
+			if err != nil {
+				t.Fatalf("delete index failed, err=%v", err)
+			}
+			if !deleted {
+				t.Fatalf("delete index failed, deleted=%v", deleted)
+			}
+		}
+	})
+
+	req := create.NewRequest()
+	req.Aliases = map[string]types.Alias{alias: {IsWriteIndex: proto.Bool(true)}}
+	if _, err := es.IndexCreate(context.Background(), firstIndex, req); err != nil {
+		t.Fatalf("create index failed, err=%v", err)
+	}
+	for _, id := range []string{"1", "2"} {
+		if _, err := es.DocCreateRefresh(context.Background(), alias, id, map[string]any{"title": id}, refresh.True); err != nil {
+			t.Fatalf("create doc failed, err=%v", err)
+		}
+	}
+
+	conditions := &RolloverConditions{MaxDocs: 2, MaxAge: "7d"}
+	rsp, err := es.Rollover(context.Background(), alias, conditions, &RolloverOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("rollover failed, err=%v", err)
+	}
+	t.Logf("rsp=%+v matched=%v", rsp, rsp.MatchedConditions())
+	if rsp.RolledOver || !rsp.DryRun || rsp.NewIndex != secondIndex {
+		t.Fatalf("unexpected dry run %+v", rsp)
+	}
+	if matched := rsp.MatchedConditions(); len(matched) != 1 || matched[0] != "[max_docs: 2]" {
+		t.Fatalf("unexpected matched conditions %v", matched)
+	}
+
+	rsp, err = es.Rollover(context.Background(), alias, conditions, nil)
+	if err != nil {
+		t.Fatalf("rollover failed, err=%v", err)
+	}
+	if !rsp.RolledOver || rsp.OldIndex != firstIndex || rsp.NewIndex != secondIndex {
+		t.Fatalf("unexpected rollover %+v", rsp)
+	}
+
+	// the new index is empty, the roller only rolls over once a document is written
+	roller := es.NewAutoRoller(alias, RolloverConditions{MaxDocs: 1}, 100*time.Millisecond)
+	rolled := make(chan *RolloverResponse, 1)
+	roller.OnRollover = func(rsp *RolloverResponse) {
+		select {
+		case rolled <- rsp:
+		default:
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- roller.Run(ctx)
+	}()
+	if _, err := es.DocCreateRefresh(context.Background(), alias, "3", map[string]any{"title": "3"}, refresh.True); err != nil {
+		t.Fatalf("create doc failed, err=%v", err)
+	}
+	select {
+	case rsp := <-rolled:
+		if rsp.NewIndex != thirdIndex {
+			t.Fatalf("unexpected auto rollover %+v", rsp)
+		}
+	case err := <-runErr:
+		t.Fatalf("auto roller failed, err=%v", err)
+	case <-ctx.Done():
+		t.Fatalf("auto roller did not roll over")
+	}
+	cancel()
+	if err := <-runErr; err != context.Canceled {
+		t.Fatalf("auto roller failed, err=%v", err)
+	}
+}
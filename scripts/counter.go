@@ -0,0 +1,60 @@
+package scripts
+
+// gen script name by script; name keep update with script
+var CounterScriptName = ScriptName(CounterScript)
+
+// CounterField increase the numeric field Name by Delta, Name can be a dotted path to a nested field.
+// Missing field (and missing parent objects) start from zero.
+// The result is clamped into [Min, Max] if they are set, an integer counter stays an integer,
+// a fractional bound is rounded into the range.
+type CounterField struct {
+	Name  string   `json:"name"`
+	Delta any      `json:"delta"`
+	Min   *float64 `json:"min,omitempty"`
+	Max   *float64 `json:"max,omitempty"`
+}
+
+// IncrBy integer counter field
+func IncrBy(name string, delta int64) CounterField {
+	return CounterField{Name: name, Delta: delta}
+}
+
+// IncrByFloat float counter field
+func IncrByFloat(name string, delta float64) CounterField {
+	return CounterField{Name: name, Delta: delta}
+}
+
+// WithMin clamp the result to be no less than min
+func (f CounterField) WithMin(min float64) CounterField {
+	f.Min = &min
+	return f
+}
+
+// WithMax clamp the result to be no greater than max
+func (f CounterField) WithMax(max float64) CounterField {
+	f.Max = &max
+	return f
+}
+
+const CounterScript = `
+	for (item in params.fields) {
+		String[] path = item['name'].splitOnToken('.');
+		Map m = ctx._source;
+		for (int i = 0; i < path.length - 1; i++) {
+			if (m[path[i]] == null) {
+				m[path[i]] = new HashMap();
+			}
+			m = (Map) m[path[i]];
+		}
+		String key = path[path.length - 1];
+		def v = (m[key] == null ? item['delta'] : m[key] + item['delta']);
+		boolean integral = !(v instanceof Double || v instanceof Float);
+		if (item['min'] != null && v < item['min']) {
+			v = integral ? (long) Math.ceil(item['min']) : item['min'];
+		}
+		if (item['max'] != null && v > item['max']) {
+			v = integral ? (long) Math.floor(item['max']) : item['max'];
+		}
+		m[key] = v;
+	}
+`
@@ -7,42 +7,228 @@ import (
 )
 
 // gen script name by script; name keep update with script
-var UpdateTypesScriptName = func() string {
+var UpdateTypesScriptName = ScriptName(UpdateTypesScript)
+
+// ScriptName gen the stored script id by the content hash of the script source
+func ScriptName(source string) string {
 	h := sha1.New()
-	_, _ = io.WriteString(h, UpdateTypesScript)
+	_, _ = io.WriteString(h, source)
 	return hex.EncodeToString(h.Sum(nil))
-}()
+}
 
 type UpdateType string
 
 const (
-	UpdateType_undefined UpdateType = ""
-	UpdateType_set       UpdateType = "set"
-	UpdateType_incr      UpdateType = "incr"
-	UpdateType_push      UpdateType = "push"
+	UpdateType_undefined   UpdateType = ""
+	UpdateType_set         UpdateType = "set"
+	UpdateType_incr        UpdateType = "incr"
+	UpdateType_push        UpdateType = "push"
+	UpdateType_unset       UpdateType = "unset"         // remove the field
+	UpdateType_addToSet    UpdateType = "add_to_set"    // push the values not already in the array
+	UpdateType_pull        UpdateType = "pull"          // remove all the array elements equal to one of the values
+	UpdateType_min         UpdateType = "min"           // set the field if the value is less than the current value
+	UpdateType_max         UpdateType = "max"           // set the field if the value is greater than the current value
+	UpdateType_mul         UpdateType = "mul"           // multiply the field by the value, missing field is set to zero
+	UpdateType_setIfAbsent UpdateType = "set_if_absent" // set the field only if it is missing or null
+	UpdateType_rename      UpdateType = "rename"        // move the field to the path given as value
+	UpdateType_mapMerge    UpdateType = "map_merge"     // shallow merge the value map into the object field
 )
 
+// UpdateField one update operation, Name can be a dotted path to a nested field,
+// missing parent objects are created, except for unset, pull and rename which are noop on missing fields
 type UpdateField struct {
 	Tp    UpdateType `json:"tp"`
 	Name  string     `json:"name"`
 	Value any        `json:"value"`
 }
 
+// UpdateFields the typed builder of update operations, e.g. scripts.Set("a.b", v).Incr("c", 1)
+type UpdateFields []UpdateField
+
+func (f UpdateFields) add(tp UpdateType, name string, value any) UpdateFields {
+	return append(f, UpdateField{Tp: tp, Name: name, Value: value})
+}
+
+func (f UpdateFields) Set(name string, value any) UpdateFields {
+	return f.add(UpdateType_set, name, value)
+}
+
+func (f UpdateFields) Incr(name string, delta any) UpdateFields {
+	return f.add(UpdateType_incr, name, delta)
+}
+
+func (f UpdateFields) Push(name string, values ...any) UpdateFields {
+	return f.add(UpdateType_push, name, values)
+}
+
+func (f UpdateFields) Unset(name string) UpdateFields {
+	return f.add(UpdateType_unset, name, nil)
+}
+
+func (f UpdateFields) AddToSet(name string, values ...any) UpdateFields {
+	return f.add(UpdateType_addToSet, name, values)
+}
+
+func (f UpdateFields) Pull(name string, values ...any) UpdateFields {
+	return f.add(UpdateType_pull, name, values)
+}
+
+func (f UpdateFields) Min(name string, value any) UpdateFields {
+	return f.add(UpdateType_min, name, value)
+}
+
+func (f UpdateFields) Max(name string, value any) UpdateFields {
+	return f.add(UpdateType_max, name, value)
+}
+
+func (f UpdateFields) Mul(name string, factor any) UpdateFields {
+	return f.add(UpdateType_mul, name, factor)
+}
+
+func (f UpdateFields) SetIfAbsent(name string, value any) UpdateFields {
+	return f.add(UpdateType_setIfAbsent, name, value)
+}
+
+func (f UpdateFields) Rename(name, newName string) UpdateFields {
+	return f.add(UpdateType_rename, name, newName)
+}
+
+func (f UpdateFields) MapMerge(name string, value map[string]any) UpdateFields {
+	return f.add(UpdateType_mapMerge, name, value)
+}
+
+func Set(name string, value any) UpdateFields {
+	return UpdateFields{}.Set(name, value)
+}
+
+func Incr(name string, delta any) UpdateFields {
+	return UpdateFields{}.Incr(name, delta)
+}
+
+func Push(name string, values ...any) UpdateFields {
+	return UpdateFields{}.Push(name, values...)
+}
+
+func Unset(name string) UpdateFields {
+	return UpdateFields{}.Unset(name)
+}
+
+func AddToSet(name string, values ...any) UpdateFields {
+	return UpdateFields{}.AddToSet(name, values...)
+}
+
+func Pull(name string, values ...any) UpdateFields {
+	return UpdateFields{}.Pull(name, values...)
+}
+
+func Min(name string, value any) UpdateFields {
+	return UpdateFields{}.Min(name, value)
+}
+
+func Max(name string, value any) UpdateFields {
+	return UpdateFields{}.Max(name, value)
+}
+
+func Mul(name string, factor any) UpdateFields {
+	return UpdateFields{}.Mul(name, factor)
+}
+
+func SetIfAbsent(name string, value any) UpdateFields {
+	return UpdateFields{}.SetIfAbsent(name, value)
+}
+
+func Rename(name, newName string) UpdateFields {
+	return UpdateFields{}.Rename(name, newName)
+}
+
+func MapMerge(name string, value map[string]any) UpdateFields {
+	return UpdateFields{}.MapMerge(name, value)
+}
+
 const UpdateTypesScript = `
+	Map parent(Map root, String[] path, boolean create) {
+		Map m = root;
+		for (int i = 0; i < path.length - 1; i++) {
+			if (m[path[i]] == null) {
+				if (!create) {
+					return null;
+				}
+				m[path[i]] = new HashMap();
+			}
+			m = (Map) m[path[i]];
+		}
+		return m;
+	}
+
 	for (item in params.fields) {
-		int tp = item['tp'];
+		String tp = item['tp'];
+		String[] path = item['name'].splitOnToken('.');
+		String key = path[path.length - 1];
+		Map m = parent(ctx._source, path, tp != 'unset' && tp != 'pull' && tp != 'rename');
+		if (m == null) {
+			continue;
+		}
+		def cur = m[key];
+		def value = item['value'];
 		if (tp == 'set') {
-			ctx._source[item['name']] = item['value'];
+			m[key] = value;
 		} else if (tp == 'incr') {
-			ctx._source[item['name']] = (ctx._source[item['name']] == null? item['value']: ctx._source[item['name']]+item['value']);
+			m[key] = (cur == null ? value : cur + value);
 		} else if (tp == 'push') {
-			if (ctx._source[item['name']] == null) {
-				ctx._source[item['name']] = item['value'];
+			if (cur == null) {
+				m[key] = value;
 			} else {
-				for (v in item['value']) {
-					ctx._source[item['name']].add(v);
+				for (v in value) {
+					cur.add(v);
+				}
+			}
+		} else if (tp == 'unset') {
+			m.remove(key);
+		} else if (tp == 'add_to_set') {
+			if (cur == null) {
+				cur = new ArrayList();
+				m[key] = cur;
+			}
+			for (v in value) {
+				if (!cur.contains(v)) {
+					cur.add(v);
 				}
 			}
+		} else if (tp == 'pull') {
+			if (cur != null) {
+				Iterator it = cur.iterator();
+				while (it.hasNext()) {
+					if (value.contains(it.next())) {
+						it.remove();
+					}
+				}
+			}
+		} else if (tp == 'min') {
+			if (cur == null || value < cur) {
+				m[key] = value;
+			}
+		} else if (tp == 'max') {
+			if (cur == null || value > cur) {
+				m[key] = value;
+			}
+		} else if (tp == 'mul') {
+			m[key] = (cur == null ? value * 0 : cur * value);
+		} else if (tp == 'set_if_absent') {
+			if (cur == null) {
+				m[key] = value;
+			}
+		} else if (tp == 'rename') {
+			if (m.containsKey(key)) {
+				def moved = m.remove(key);
+				String[] to = value.splitOnToken('.');
+				parent(ctx._source, to, true)[to[to.length - 1]] = moved;
+			}
+		} else if (tp == 'map_merge') {
+			if (cur == null) {
+				m[key] = value;
+			} else {
+				cur.putAll(value);
+			}
 		}
 	}
 `
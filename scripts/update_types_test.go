@@ -0,0 +1,29 @@
+package scripts
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestUpdateFieldsBuilder(t *testing.T) {
+	fields := Set("a.b", 1).Incr("c", 2).AddToSet("tags", "x", "y").Unset("d").Rename("e", "f.g")
+	buf, err := json.Marshal(fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `[{"tp":"set","name":"a.b","value":1},{"tp":"incr","name":"c","value":2},` +
+		`{"tp":"add_to_set","name":"tags","value":["x","y"]},{"tp":"unset","name":"d","value":null},` +
+		`{"tp":"rename","name":"e","value":"f.g"}]`
+	if string(buf) != expected {
+		t.Fatalf("got %s, expected %s", buf, expected)
+	}
+}
+
+func TestScriptName(t *testing.T) {
+	if UpdateTypesScriptName != ScriptName(UpdateTypesScript) {
+		t.Fatalf("UpdateTypesScriptName should be the hash of UpdateTypesScript")
+	}
+	if ScriptName(UpdateTypesScript+" ") == UpdateTypesScriptName {
+		t.Fatalf("script name should change with the script source")
+	}
+}
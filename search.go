@@ -79,8 +79,9 @@ func (resp *UntypedSearchResponse[_, _, Sort]) LastSort() (s Sort) {
 // https://www.elastic.co/guide/en/elasticsearch/reference/master/search-search.html
 // Index A comma-separated list of index names to search; use `_all` or empty string
 // to perform the operation on all indices
-func (es *ElasticsearchEx) SearchByIndex(ctx context.Context, index string, searchRequest *search.Request, dest FromJSON) error {
-	req := es.Core.Search().Index(index).Request(searchRequest)
+// supported options: WithRouting, only the shards of the routing values are searched
+func (es *ElasticsearchEx) SearchByIndex(ctx context.Context, index string, searchRequest *search.Request, dest FromJSON, opts ...RequestOption) error {
+	req := applyRouting(es.Core.Search().Index(index).Request(searchRequest), newRequestOptions(opts))
 	err := doGetResponse[ErrGeneric](ctx, req, dest)
 	return err
 }
@@ -98,8 +99,8 @@ func (es *ElasticsearchEx) SearchByIndex(ctx context.Context, index string, sear
 //	   }
 //	 }
 //	}
-func (es *ElasticsearchEx) SearchByIndexRaw(ctx context.Context, index string, sr []byte, dest FromJSON) error {
-	req := es.Core.Search().Index(index).Raw(sr)
+func (es *ElasticsearchEx) SearchByIndexRaw(ctx context.Context, index string, sr []byte, dest FromJSON, opts ...RequestOption) error {
+	req := applyRouting(es.Core.Search().Index(index).Raw(sr), newRequestOptions(opts))
 	err := doGetResponse[ErrGeneric](ctx, req, dest)
 	return err
 }
@@ -113,7 +114,7 @@ type Pit struct {
 // sort like: [ {"date": "asc"}, {"tie_breaker_id": "asc"} ]
 // https://www.elastic.co/guide/en/elasticsearch/reference/current/paginate-search-results.html#search-after
 func (es *ElasticsearchEx) SearchByIndexPaginated(ctx context.Context, index string, searchRequest *search.Request, dest FromJSON,
-	size int64, searchAfter types.SortResults, sorts types.Sort,
+	size int64, searchAfter types.SortResults, sorts types.Sort, opts ...RequestOption,
 ) error {
 	if len(searchAfter) > 0 {
 		searchRequest.SearchAfter = searchAfter
@@ -122,7 +123,7 @@ func (es *ElasticsearchEx) SearchByIndexPaginated(ctx context.Context, index str
 		searchRequest.Sort = sorts
 	}
 
-	req := es.Core.Search().Index(index).Request(searchRequest)
+	req := applyRouting(es.Core.Search().Index(index).Request(searchRequest), newRequestOptions(opts))
 	err := doGetResponse[ErrGeneric](ctx, req, dest)
 	return err
 }
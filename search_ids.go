@@ -54,18 +54,22 @@ func (m *DocsResponse[T]) FromJSON(i []byte) error {
 
 var _ FromJSON = (*DocsResponse[any])(nil)
 
-func (es *ElasticsearchEx) GetDocumentByIDs(ctx context.Context, index string, ids []string, dest FromJSON) error {
+// GetDocumentByIDs get documents by ids from a single index, see MGet for a typed multi-index variant
+// supported options: WithRouting, all the documents must share the same routing
+func (es *ElasticsearchEx) GetDocumentByIDs(ctx context.Context, index string, ids []string, dest FromJSON, opts ...RequestOption) error {
 	// es.Client.Mget
 	payload, err := json.Marshal(&MgetRequest{IDs: ids})
 	if err != nil {
 		return err
 	}
 
-	r := esapi.MgetRequest{Body: bytes.NewReader(payload), Index: index}
+	o := newRequestOptions(opts)
+	r := esapi.MgetRequest{Body: bytes.NewReader(payload), Index: index, Routing: o.routing}
 	res, err := r.Do(ctx, es.Client.Transport)
 	if err != nil {
 		return err
 	}
+	defer res.Body.Close()
 
 	body, err := io.ReadAll(res.Body)
 	if err != nil {
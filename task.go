@@ -0,0 +1,222 @@
+package elastic_wrapper
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const defaultTaskPollInterval = time.Second
+
+// BulkByScrollStatus the progress of delete by query, update by query and reindex tasks
+type BulkByScrollStatus struct {
+	Total            int64 `json:"total"`
+	Updated          int64 `json:"updated"`
+	Created          int64 `json:"created"`
+	Deleted          int64 `json:"deleted"`
+	Batches          int64 `json:"batches"`
+	VersionConflicts int64 `json:"version_conflicts"`
+	Noops            int64 `json:"noops"`
+	Retries          struct {
+		Bulk   int64 `json:"bulk"`
+		Search int64 `json:"search"`
+	} `json:"retries"`
+	ThrottledMillis      int64   `json:"throttled_millis"`
+	RequestsPerSecond    float64 `json:"requests_per_second"`
+	ThrottledUntilMillis int64   `json:"throttled_until_millis"`
+}
+
+// Done the number of documents already processed
+func (s BulkByScrollStatus) Done() int64 {
+	return s.Updated + s.Created + s.Deleted + s.VersionConflicts + s.Noops
+}
+
+type ErrorCause struct {
+	Type   string `json:"type"`
+	Reason string `json:"reason"`
+}
+
+// BulkByScrollFailure is either a bulk failure (Id, Cause) or a search failure (Shard, Node, Reason)
+type BulkByScrollFailure struct {
+	Index  string      `json:"index"`
+	Id     string      `json:"id,omitempty"`
+	Cause  *ErrorCause `json:"cause,omitempty"`
+	Status int         `json:"status,omitempty"`
+	Shard  *int        `json:"shard,omitempty"`
+	Node   string      `json:"node,omitempty"`
+	Reason *ErrorCause `json:"reason,omitempty"`
+}
+
+// BulkByScrollResponse the final summary of delete by query, update by query and reindex tasks
+type BulkByScrollResponse struct {
+	BulkByScrollStatus
+	Took     int64                 `json:"took"`
+	TimedOut bool                  `json:"timed_out"`
+	Failures []BulkByScrollFailure `json:"failures"`
+}
+
+func (r *BulkByScrollResponse) FromJSON(bytes []byte) error {
+	return FromJSONImplDefault(bytes, r)
+}
+
+// ErrBulkByScrollFailures the task completed, but some documents or shards failed
+type ErrBulkByScrollFailures struct {
+	Failures []BulkByScrollFailure
+}
+
+func (e ErrBulkByScrollFailures) Error() string {
+	first := e.Failures[0]
+	cause := first.Cause
+	if cause == nil {
+		cause = first.Reason
+	}
+	if cause == nil {
+		cause = &ErrorCause{}
+	}
+	return fmt.Sprintf("task completed with %d failures, first failure: index=%s id=%s type=%s reason=%s",
+		len(e.Failures), first.Index, first.Id, cause.Type, cause.Reason)
+}
+
+type TaskGetResponse struct {
+	Completed bool `json:"completed"`
+	Task      struct {
+		Node               string             `json:"node"`
+		Id                 int64              `json:"id"`
+		Type               string             `json:"type"`
+		Action             string             `json:"action"`
+		Description        string             `json:"description"`
+		StartTimeInMillis  int64              `json:"start_time_in_millis"`
+		RunningTimeInNanos int64              `json:"running_time_in_nanos"`
+		Cancellable        bool               `json:"cancellable"`
+		Cancelled          bool               `json:"cancelled"`
+		Status             BulkByScrollStatus `json:"status"`
+	} `json:"task"`
+	Response *BulkByScrollResponse `json:"response"`
+	Error    ErrGeneric            `json:"error"`
+}
+
+func (t *TaskGetResponse) FromJSON(bytes []byte) error {
+	return FromJSONImplDefault(bytes, t)
+}
+
+type taskStartResponse struct {
+	Task string `json:"task"`
+}
+
+func (t *taskStartResponse) FromJSON(bytes []byte) error {
+	return FromJSONImplDefault(bytes, t)
+}
+
+// Task the handle of a background delete by query, update by query or reindex task,
+// started with wait_for_completion=false
+type Task struct {
+	ID           string
+	PollInterval time.Duration // the interval of progress polling in Wait, default 1s
+
+	es         *ElasticsearchEx
+	rethrottle func(ctx context.Context, taskID, rps string) error
+}
+
+func newTask(es *ElasticsearchEx, id string, rethrottle func(ctx context.Context, taskID, rps string) error) *Task {
+	return &Task{
+		ID:           id,
+		PollInterval: defaultTaskPollInterval,
+		es:           es,
+		rethrottle:   rethrottle,
+	}
+}
+
+// Get the current status of the task, progress is in Task.Status, and Response is set once completed
+func (t *Task) Get(ctx context.Context) (*TaskGetResponse, error) {
+	var rsp TaskGetResponse
+	err := doGetResponse[ErrGeneric](ctx, t.es.Tasks.Get(t.ID), &rsp)
+	return &rsp, err
+}
+
+// Progress the current progress of the task
+func (t *Task) Progress(ctx context.Context) (*BulkByScrollStatus, error) {
+	rsp, err := t.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if rsp.Completed && rsp.Response != nil {
+		return &rsp.Response.BulkByScrollStatus, nil
+	}
+	return &rsp.Task.Status, nil
+}
+
+// Wait poll the task until it completes, see WaitProgress
+func (t *Task) Wait(ctx context.Context) (*BulkByScrollResponse, error) {
+	return t.WaitProgress(ctx, nil)
+}
+
+// WaitProgress poll the task every PollInterval until it completes, onProgress is called with every polled status.
+// The final summary is returned, together with ErrBulkByScrollFailures if there are any failures
+func (t *Task) WaitProgress(ctx context.Context, onProgress func(status *BulkByScrollStatus)) (*BulkByScrollResponse, error) {
+	var rsp *TaskGetResponse
+	err := pollTask(ctx, t.PollInterval, func(ctx context.Context) (bool, error) {
+		var err error
+		if rsp, err = t.Get(ctx); err != nil {
+			return false, err
+		}
+		if !rsp.Completed && onProgress != nil {
+			onProgress(&rsp.Task.Status)
+		}
+		return rsp.Completed, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rsp.Error) > 0 {
+		return rsp.Response, rsp.Error
+	}
+	if rsp.Response == nil {
+		return nil, fmt.Errorf("task %s completed without response", t.ID)
+	}
+	if onProgress != nil {
+		onProgress(&rsp.Response.BulkByScrollStatus)
+	}
+	if len(rsp.Response.Failures) > 0 {
+		return rsp.Response, ErrBulkByScrollFailures{Failures: rsp.Response.Failures}
+	}
+	return rsp.Response, nil
+}
+
+// pollTask call poll every interval, default 1s, until it reports the task completed, fails or ctx is done
+func pollTask(ctx context.Context, interval time.Duration, poll func(ctx context.Context) (completed bool, err error)) error {
+	if interval <= 0 {
+		interval = defaultTaskPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		completed, err := poll(ctx)
+		if err != nil || completed {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Rethrottle change the requests per second of the running task, -1 to disable throttling
+func (t *Task) Rethrottle(ctx context.Context, requestsPerSecond float64) error {
+	return t.rethrottle(ctx, t.ID, formatRequestsPerSecond(requestsPerSecond))
+}
+
+// Cancel the task, the documents already processed are not reverted
+func (t *Task) Cancel(ctx context.Context) error {
+	var rsp ResponseGeneric
+	return doGetResponse[ErrGeneric](ctx, t.es.Tasks.Cancel().TaskId(t.ID), &rsp)
+}
+
+func formatRequestsPerSecond(requestsPerSecond float64) string {
+	return strconv.FormatFloat(requestsPerSecond, 'f', -1, 64)
+}
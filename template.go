@@ -0,0 +1,371 @@
+package elastic_wrapper
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+)
+
+// the _meta key of the content hash, which makes SyncTemplates idempotent
+const templateHashMetaKey = "elastic_wrapper_hash"
+
+const (
+	lifecyclePoliciesDir  = "ilm_policies"
+	componentTemplatesDir = "component_templates"
+	indexTemplatesDir     = "index_templates"
+)
+
+// ComponentTemplate a reusable building block of index templates
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/indices-component-template.html
+type ComponentTemplate struct {
+	Template types.IndexTemplateMapping `json:"template"`
+	Version  *int64                     `json:"version,omitempty"`
+	Meta     map[string]any             `json:"_meta,omitempty"`
+}
+
+// IndexTemplate a composable index template, applied to the new indices matching IndexPatterns
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/index-templates.html
+type IndexTemplate struct {
+	IndexPatterns   []string                    `json:"index_patterns"`
+	ComposedOf      []string                    `json:"composed_of,omitempty"` // component templates, merged in order
+	Template        *types.IndexTemplateMapping `json:"template,omitempty"`    // overrides the component templates
+	Priority        *int                        `json:"priority,omitempty"`
+	Version         *int64                      `json:"version,omitempty"`
+	Meta            map[string]any              `json:"_meta,omitempty"`
+	DataStream      *types.DataStreamVisibility `json:"data_stream,omitempty"`
+	AllowAutoCreate *bool                       `json:"allow_auto_create,omitempty"`
+}
+
+// TemplateState the settings, mappings and aliases of a template as returned by es
+type TemplateState struct {
+	Settings map[string]any                   `json:"settings"`
+	Mappings map[string]any                   `json:"mappings"`
+	Aliases  map[string]types.AliasDefinition `json:"aliases"`
+}
+
+type ComponentTemplateInfo struct {
+	Name              string `json:"name"`
+	ComponentTemplate struct {
+		Template TemplateState  `json:"template"`
+		Version  *int64         `json:"version"`
+		Meta     map[string]any `json:"_meta"`
+	} `json:"component_template"`
+}
+
+type GetComponentTemplatesResponse struct {
+	ComponentTemplates []ComponentTemplateInfo `json:"component_templates"`
+}
+
+func (g *GetComponentTemplatesResponse) FromJSON(bytes []byte) error {
+	return FromJSONImplDefault(bytes, g)
+}
+
+type IndexTemplateInfo struct {
+	Name          string `json:"name"`
+	IndexTemplate struct {
+		IndexPatterns []string       `json:"index_patterns"`
+		ComposedOf    []string       `json:"composed_of"`
+		Template      TemplateState  `json:"template"`
+		Priority      *int           `json:"priority"`
+		Version       *int64         `json:"version"`
+		Meta          map[string]any `json:"_meta"`
+		DataStream    map[string]any `json:"data_stream"`
+	} `json:"index_template"`
+}
+
+type GetIndexTemplatesResponse struct {
+	IndexTemplates []IndexTemplateInfo `json:"index_templates"`
+}
+
+func (g *GetIndexTemplatesResponse) FromJSON(bytes []byte) error {
+	return FromJSONImplDefault(bytes, g)
+}
+
+// SimulateIndexResponse the effective template of an index name, Overlapping lists the lower priority matching templates
+type SimulateIndexResponse struct {
+	Template    TemplateState `json:"template"`
+	Overlapping []struct {
+		Name          string   `json:"name"`
+		IndexPatterns []string `json:"index_patterns"`
+	} `json:"overlapping"`
+}
+
+func (s *SimulateIndexResponse) FromJSON(bytes []byte) error {
+	return FromJSONImplDefault(bytes, s)
+}
+
+// PutComponentTemplate create or update the component template
+func (es *ElasticsearchEx) PutComponentTemplate(ctx context.Context, name string, tmpl *ComponentTemplate) (*AcknowledgedResponse, error) {
+	body, err := json.Marshal(tmpl)
+	if err != nil {
+		return nil, err
+	}
+	return es.PutComponentTemplateRaw(ctx, name, body)
+}
+
+// PutComponentTemplateRaw create or update the component template by the JSON definition
+func (es *ElasticsearchEx) PutComponentTemplateRaw(ctx context.Context, name string, tmpl []byte) (*AcknowledgedResponse, error) {
+	var rsp AcknowledgedResponse
+	err := doGetResponse[ErrGeneric](ctx, es.Cluster.PutComponentTemplate(name).Raw(tmpl), &rsp)
+	return &rsp, err
+}
+
+// GetComponentTemplates get the component templates by name, wildcards are supported, all if name is empty.
+// ErrNotFound if none matches
+func (es *ElasticsearchEx) GetComponentTemplates(ctx context.Context, name string) (*GetComponentTemplatesResponse, error) {
+	req := es.Cluster.GetComponentTemplate()
+	if name != "" {
+		req.Name(name)
+	}
+	var rsp GetComponentTemplatesResponse
+	err := doGetResponse[ErrGeneric](ctx, req, &rsp)
+	return &rsp, err
+}
+
+// DeleteComponentTemplate ErrNotFound if not exists, it fails if the template is used by an index template
+func (es *ElasticsearchEx) DeleteComponentTemplate(ctx context.Context, name string) (*AcknowledgedResponse, error) {
+	var rsp AcknowledgedResponse
+	err := doGetResponse[ErrGeneric](ctx, es.Cluster.DeleteComponentTemplate(name), &rsp)
+	return &rsp, err
+}
+
+func (es *ElasticsearchEx) ComponentTemplateExists(ctx context.Context, name string) (bool, error) {
+	return es.Cluster.ExistsComponentTemplate(name).IsSuccess(ctx)
+}
+
+// PutIndexTemplate create or update the composable index template, the component templates must exist
+func (es *ElasticsearchEx) PutIndexTemplate(ctx context.Context, name string, tmpl *IndexTemplate) (*AcknowledgedResponse, error) {
+	body, err := json.Marshal(tmpl)
+	if err != nil {
+		return nil, err
+	}
+	return es.PutIndexTemplateRaw(ctx, name, body)
+}
+
+// PutIndexTemplateRaw create or update the composable index template by the JSON definition
+func (es *ElasticsearchEx) PutIndexTemplateRaw(ctx context.Context, name string, tmpl []byte) (*AcknowledgedResponse, error) {
+	var rsp AcknowledgedResponse
+	err := doGetResponse[ErrGeneric](ctx, es.Indices.PutIndexTemplate(name).Raw(tmpl), &rsp)
+	return &rsp, err
+}
+
+// GetIndexTemplates get the composable index templates by name, wildcards are supported, all if name is empty.
+// ErrNotFound if none matches
+func (es *ElasticsearchEx) GetIndexTemplates(ctx context.Context, name string) (*GetIndexTemplatesResponse, error) {
+	req := es.Indices.GetIndexTemplate()
+	if name != "" {
+		req.Name(name)
+	}
+	var rsp GetIndexTemplatesResponse
+	err := doGetResponse[ErrGeneric](ctx, req, &rsp)
+	return &rsp, err
+}
+
+// DeleteIndexTemplate ErrNotFound if not exists
+func (es *ElasticsearchEx) DeleteIndexTemplate(ctx context.Context, name string) (*AcknowledgedResponse, error) {
+	var rsp AcknowledgedResponse
+	err := doGetResponse[ErrGeneric](ctx, es.Indices.DeleteIndexTemplate(name), &rsp)
+	return &rsp, err
+}
+
+func (es *ElasticsearchEx) IndexTemplateExists(ctx context.Context, name string) (bool, error) {
+	return es.Indices.ExistsIndexTemplate(name).IsSuccess(ctx)
+}
+
+// SimulateIndex the effective settings, mappings and aliases a new index of the name would get from the templates,
+// no index is created
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/indices-simulate-index.html
+func (es *ElasticsearchEx) SimulateIndex(ctx context.Context, indexName string) (*SimulateIndexResponse, error) {
+	var rsp SimulateIndexResponse
+	err := doGetResponse[ErrGeneric](ctx, es.Indices.SimulateIndexTemplate(indexName), &rsp)
+	return &rsp, err
+}
+
+// TemplateSet the templates to sync, the values are *LifecyclePolicy / *ComponentTemplate / *IndexTemplate,
+// or the JSON definitions as []byte or json.RawMessage
+type TemplateSet struct {
+	LifecyclePolicies  map[string]any // the ILM policies referenced by index.lifecycle.name of the templates
+	ComponentTemplates map[string]any
+	IndexTemplates     map[string]any
+}
+
+// LoadTemplateSet load the JSON definitions from ilm_policies/<name>.json, component_templates/<name>.json
+// and index_templates/<name>.json of fsys, e.g. an embed.FS:
+//
+//	//go:embed templates
+//	var templatesFS embed.FS
+//
+//	sub, _ := fs.Sub(templatesFS, "templates")
+//	set, err := LoadTemplateSet(sub)
+func LoadTemplateSet(fsys fs.FS) (*TemplateSet, error) {
+	set := &TemplateSet{
+		LifecyclePolicies:  map[string]any{},
+		ComponentTemplates: map[string]any{},
+		IndexTemplates:     map[string]any{},
+	}
+	for dir, templates := range map[string]map[string]any{
+		lifecyclePoliciesDir:  set.LifecyclePolicies,
+		componentTemplatesDir: set.ComponentTemplates,
+		indexTemplatesDir:     set.IndexTemplates,
+	} {
+		entries, err := fs.ReadDir(fsys, dir)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || path.Ext(entry.Name()) != ".json" {
+				continue
+			}
+			body, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+			if err != nil {
+				return nil, err
+			}
+			if !json.Valid(body) {
+				return nil, fmt.Errorf("invalid JSON in %s/%s", dir, entry.Name())
+			}
+			templates[strings.TrimSuffix(entry.Name(), ".json")] = json.RawMessage(body)
+		}
+	}
+	return set, nil
+}
+
+// TemplateSyncResult the names of the synced templates, prefixed by ilm_policy/, component_template/ or index_template/
+type TemplateSyncResult struct {
+	Created   []string
+	Updated   []string
+	Unchanged []string
+}
+
+// SyncTemplates put the templates whose content changed since the last sync,
+// the ILM policies first, then the component templates and the index templates.
+// A content hash is stored in the `_meta` of every template, so unchanged templates are not put again
+func (es *ElasticsearchEx) SyncTemplates(ctx context.Context, set *TemplateSet) (*TemplateSyncResult, error) {
+	result := &TemplateSyncResult{}
+	for _, name := range sortedKeys(set.LifecyclePolicies) {
+		err := es.syncTemplate(ctx, result, "ilm_policy/"+name, set.LifecyclePolicies[name],
+			func() (map[string]any, error) {
+				rsp, err := es.GetLifecyclePolicies(ctx, name)
+				if err != nil {
+					return nil, err
+				}
+				return rsp[name].Policy.Meta, nil
+			},
+			func(body []byte) error {
+				_, err := es.PutLifecyclePolicyRaw(ctx, name, body)
+				return err
+			})
+		if err != nil {
+			return result, err
+		}
+	}
+	for _, name := range sortedKeys(set.ComponentTemplates) {
+		err := es.syncTemplate(ctx, result, "component_template/"+name, set.ComponentTemplates[name],
+			func() (map[string]any, error) {
+				rsp, err := es.GetComponentTemplates(ctx, name)
+				if err != nil || len(rsp.ComponentTemplates) == 0 {
+					return nil, err
+				}
+				return rsp.ComponentTemplates[0].ComponentTemplate.Meta, nil
+			},
+			func(body []byte) error {
+				_, err := es.PutComponentTemplateRaw(ctx, name, body)
+				return err
+			})
+		if err != nil {
+			return result, err
+		}
+	}
+	for _, name := range sortedKeys(set.IndexTemplates) {
+		err := es.syncTemplate(ctx, result, "index_template/"+name, set.IndexTemplates[name],
+			func() (map[string]any, error) {
+				rsp, err := es.GetIndexTemplates(ctx, name)
+				if err != nil || len(rsp.IndexTemplates) == 0 {
+					return nil, err
+				}
+				return rsp.IndexTemplates[0].IndexTemplate.Meta, nil
+			},
+			func(body []byte) error {
+				_, err := es.PutIndexTemplateRaw(ctx, name, body)
+				return err
+			})
+		if err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+func (es *ElasticsearchEx) syncTemplate(ctx context.Context, result *TemplateSyncResult, name string, tmpl any,
+	liveMeta func() (map[string]any, error), put func(body []byte) error,
+) error {
+	body, hash, err := hashedTemplate(tmpl)
+	if err != nil {
+		return fmt.Errorf("sync %s: %w", name, err)
+	}
+	meta, err := liveMeta()
+	exists := err == nil
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("sync %s: %w", name, err)
+	}
+	if exists && meta[templateHashMetaKey] == hash {
+		result.Unchanged = append(result.Unchanged, name)
+		return nil
+	}
+	if err := put(body); err != nil {
+		return fmt.Errorf("sync %s: %w", name, err)
+	}
+	if exists {
+		result.Updated = append(result.Updated, name)
+	} else {
+		result.Created = append(result.Created, name)
+	}
+	return nil
+}
+
+// hashedTemplate the normalized JSON body of the template with the content hash in `_meta`
+func hashedTemplate(tmpl any) ([]byte, string, error) {
+	var raw []byte
+	switch v := tmpl.(type) {
+	case json.RawMessage:
+		raw = v
+	case []byte:
+		raw = v
+	default:
+		var err error
+		if raw, err = json.Marshal(tmpl); err != nil {
+			return nil, "", err
+		}
+	}
+	var body map[string]any
+	if err := json.Unmarshal(raw, &body); err != nil {
+		return nil, "", err
+	}
+	meta, _ := body["_meta"].(map[string]any)
+	if meta == nil {
+		meta = map[string]any{}
+	}
+	delete(meta, templateHashMetaKey)
+	body["_meta"] = meta
+
+	// map keys are sorted by encoding/json, so the same content always has the same hash
+	normalized, err := json.Marshal(body)
+	if err != nil {
+		return nil, "", err
+	}
+	sum := sha1.Sum(normalized)
+	hash := hex.EncodeToString(sum[:])
+	meta[templateHashMetaKey] = hash
+	out, err := json.Marshal(body)
+	return out, hash, err
+}
@@ -0,0 +1,169 @@
+package elastic_wrapper
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"testing/fstest"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+)
+
+func TestHashedTemplate(t *testing.T) {
+	priority := 100
+	typed := &IndexTemplate{
+		IndexPatterns: []string{"logs-*"},
+		ComposedOf:    []string{"logs-settings"},
+		Priority:      &priority,
+		Meta:          map[string]any{"owner": "infra"},
+	}
+	raw := json.RawMessage(`{"_meta": {"owner": "infra"}, "priority": 100, "composed_of": ["logs-settings"], "index_patterns": ["logs-*"]}`)
+
+	body, hash, err := hashedTemplate(typed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, rawHash, err := hashedTemplate(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash != rawHash {
+		t.Errorf("the same content should have the same hash, typed=%s raw=%s", hash, rawHash)
+	}
+
+	// re-hashing the body with the hash in _meta gives the same hash
+	_, again, err := hashedTemplate(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again != hash {
+		t.Errorf("expect hash %s, got %s", hash, again)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	meta := decoded["_meta"].(map[string]any)
+	if meta[templateHashMetaKey] != hash || meta["owner"] != "infra" {
+		t.Errorf("unexpected _meta %v", meta)
+	}
+	if typed.Meta[templateHashMetaKey] != nil {
+		t.Errorf("the template should not be modified")
+	}
+
+	priority = 200
+	_, changed, err := hashedTemplate(typed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed == hash {
+		t.Errorf("a changed template should have a different hash")
+	}
+}
+
+func TestLoadTemplateSet(t *testing.T) {
+	fsys := fstest.MapFS{
+		"ilm_policies/logs.json":                 {Data: []byte(`{"phases": {"delete": {"min_age": "30d", "actions": {"delete": {}}}}}`)},
+		"component_templates/logs-settings.json": {Data: []byte(`{"template": {"settings": {"number_of_shards": 1}}}`)},
+		"index_templates/logs.json":              {Data: []byte(`{"index_patterns": ["logs-*"], "composed_of": ["logs-settings"]}`)},
+		"index_templates/README.md":              {Data: []byte(`# templates`)},
+	}
+	set, err := LoadTemplateSet(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(set.LifecyclePolicies) != 1 || set.LifecyclePolicies["logs"] == nil {
+		t.Errorf("unexpected lifecycle policies %v", set.LifecyclePolicies)
+	}
+	if len(set.ComponentTemplates) != 1 || set.ComponentTemplates["logs-settings"] == nil {
+		t.Errorf("unexpected component templates %v", set.ComponentTemplates)
+	}
+	if len(set.IndexTemplates) != 1 || set.IndexTemplates["logs"] == nil {
+		t.Errorf("unexpected index templates %v", set.IndexTemplates)
+	}
+
+	fsys["index_templates/broken.json"] = &fstest.MapFile{Data: []byte(`{"index_patterns": `)}
+	if _, err := LoadTemplateSet(fsys); err == nil {
+		t.Errorf("expect error for invalid JSON")
+	}
+}
+
+func TestTemplates(t *testing.T) {
+	es := newClient(t)
+
+	componentName := "test_logs_settings"
+	templateName := "test_logs"
+	demoIndex := "test_logs-2023.01.01"
+
+	t.Cleanup(func() {
+		if _, err := es.DeleteIndexTemplate(context.Background(), templateName); err != nil {
+			t.Fatalf("delete index template failed, err=%v", err)
+		}
+		if _, err := es.DeleteComponentTemplate(context.Background(), componentName); err != nil {
+			t.Fatalf("delete component template failed, err=%v", err)
+		}
+	})
+
+	component := &ComponentTemplate{
+		Template: types.IndexTemplateMapping{
+			Settings: &types.IndexSettings{NumberOfShards: "1"},
+			Mappings: &types.TypeMapping{Properties: map[string]types.Property{
+				"message": types.NewTextProperty(),
+				"level":   types.NewKeywordProperty(),
+			}},
+		},
+	}
+	priority := 100
+	set := &TemplateSet{
+		ComponentTemplates: map[string]any{componentName: component},
+		IndexTemplates: map[string]any{templateName: &IndexTemplate{
+			IndexPatterns: []string{"test_logs-*"},
+			ComposedOf:    []string{componentName},
+			Priority:      &priority,
+		}},
+	}
+	result, err := es.SyncTemplates(context.Background(), set)
+	if err != nil {
+		t.Fatalf("sync templates failed, err=%v", err)
+	}
+	t.Logf("result=%+v", result)
+
+	// nothing changed, nothing is put
+	result, err = es.SyncTemplates(context.Background(), set)
+	if err != nil {
+		t.Fatalf("sync templates failed, err=%v", err)
+	}
+	if len(result.Unchanged) != 2 || len(result.Created)+len(result.Updated) != 0 {
+		t.Fatalf("expect the templates unchanged, result=%+v", result)
+	}
+
+	component.Template.Settings.NumberOfReplicas = "0"
+	result, err = es.SyncTemplates(context.Background(), set)
+	if err != nil {
+		t.Fatalf("sync templates failed, err=%v", err)
+	}
+	if len(result.Updated) != 1 || result.Updated[0] != "component_template/"+componentName {
+		t.Fatalf("expect the component template updated, result=%+v", result)
+	}
+
+	exists, err := es.IndexTemplateExists(context.Background(), templateName)
+	if err != nil || !exists {
+		t.Fatalf("index template should exist, exists=%v err=%v", exists, err)
+	}
+	templates, err := es.GetIndexTemplates(context.Background(), templateName)
+	if err != nil {
+		t.Fatalf("get index templates failed, err=%v", err)
+	}
+	if len(templates.IndexTemplates) != 1 {
+		t.Fatalf("unexpected index templates %+v", templates)
+	}
+
+	simulated, err := es.SimulateIndex(context.Background(), demoIndex)
+	if err != nil {
+		t.Fatalf("simulate index failed, err=%v", err)
+	}
+	t.Logf("simulated=%+v", simulated.Template)
+	if _, ok := simulated.Template.Mappings["properties"].(map[string]any)["level"]; !ok {
+		t.Fatalf("expect the mapping of the component template, mappings=%v", simulated.Template.Mappings)
+	}
+}
@@ -0,0 +1,242 @@
+package elastic_wrapper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/core/termvectors"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+)
+
+// TermVectorsOptions the optional settings of term vectors, the zero value keeps the es defaults
+type TermVectorsOptions struct {
+	// Doc artificial document, the analysis runs on it instead of a stored document, the id is ignored
+	Doc              any
+	PerFieldAnalyzer map[string]string // override the field analyzer
+	Filter           *types.TermVectorsFilter
+
+	TermStatistics  bool  // return doc_freq and ttf, false by default
+	FieldStatistics *bool // true by default
+	Positions       *bool // true by default
+	Offsets         *bool // true by default
+	Payloads        *bool // true by default
+}
+
+type TermVectorToken struct {
+	Position    *int   `json:"position"`
+	StartOffset *int   `json:"start_offset"`
+	EndOffset   *int   `json:"end_offset"`
+	Payload     string `json:"payload"`
+}
+
+type TermVector struct {
+	TermFreq int               `json:"term_freq"`
+	DocFreq  *int              `json:"doc_freq"` // only with TermStatistics
+	Ttf      *int              `json:"ttf"`      // only with TermStatistics
+	Score    *float64          `json:"score"`    // only with Filter
+	Tokens   []TermVectorToken `json:"tokens"`
+}
+
+type FieldTermVectors struct {
+	FieldStatistics *struct {
+		SumDocFreq int `json:"sum_doc_freq"`
+		DocCount   int `json:"doc_count"`
+		SumTtf     int `json:"sum_ttf"`
+	} `json:"field_statistics"`
+	Terms map[string]TermVector `json:"terms"`
+}
+
+type TermVectorsResponse struct {
+	Index       string                      `json:"_index"`
+	Id          string                      `json:"_id"`
+	Version     int                         `json:"_version"`
+	Found       bool                        `json:"found"`
+	Took        int                         `json:"took"`
+	TermVectors map[string]FieldTermVectors `json:"term_vectors"`
+	Error       *ErrorCause                 `json:"error"` // only in multi term vectors
+}
+
+func (t *TermVectorsResponse) FromJSON(bytes []byte) error {
+	return FromJSONImplDefault(bytes, t)
+}
+
+// TopTerms the terms of the field ordered by score if there is a Filter, else by term_freq, n <= 0 means all
+func (t *TermVectorsResponse) TopTerms(field string, n int) []string {
+	terms := t.TermVectors[field].Terms
+	keys := make([]string, 0, len(terms))
+	for term := range terms {
+		keys = append(keys, term)
+	}
+	weight := func(term string) float64 {
+		if score := terms[term].Score; score != nil {
+			return *score
+		}
+		return float64(terms[term].TermFreq)
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		wi, wj := weight(keys[i]), weight(keys[j])
+		if wi != wj {
+			return wi > wj
+		}
+		return keys[i] < keys[j]
+	})
+	if n > 0 && len(keys) > n {
+		keys = keys[:n]
+	}
+	return keys
+}
+
+// TermVectors returns information and statistics about terms in the fields of a document,
+// set tvOpts.Doc to analyze an artificial document which is not indexed
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/docs-termvectors.html
+// supported options: WithRouting, WithPreference, WithRealtime
+func (es *ElasticsearchEx) TermVectors(ctx context.Context, index, id string, fields []string, tvOpts *TermVectorsOptions, opts ...RequestOption) (*TermVectorsResponse, error) {
+	if tvOpts == nil {
+		tvOpts = &TermVectorsOptions{}
+	}
+	if tvOpts.Doc == nil && id == "" {
+		return nil, fmt.Errorf("term vectors needs either a document id or an artificial document")
+	}
+
+	request := termvectors.NewRequest()
+	request.Doc = tvOpts.Doc
+	request.Filter = tvOpts.Filter
+	request.PerFieldAnalyzer = tvOpts.PerFieldAnalyzer
+
+	o := newRequestOptions(opts)
+	req := applyRouting(es.Core.Termvectors(index).Request(request), o)
+	if tvOpts.Doc == nil {
+		req.Id(id)
+	}
+	if len(fields) > 0 {
+		req.Fields(strings.Join(fields, ","))
+	}
+	if tvOpts.TermStatistics {
+		req.TermStatistics(true)
+	}
+	if tvOpts.FieldStatistics != nil {
+		req.FieldStatistics(*tvOpts.FieldStatistics)
+	}
+	if tvOpts.Positions != nil {
+		req.Positions(*tvOpts.Positions)
+	}
+	if tvOpts.Offsets != nil {
+		req.Offsets(*tvOpts.Offsets)
+	}
+	if tvOpts.Payloads != nil {
+		req.Payloads(*tvOpts.Payloads)
+	}
+	if o.preference != "" {
+		req.Preference(o.preference)
+	}
+	if o.realtime != nil {
+		req.Realtime(*o.realtime)
+	}
+
+	var rsp TermVectorsResponse
+	err := doGetResponse[ErrGeneric](ctx, req, &rsp)
+	return &rsp, err
+}
+
+// TermVectorsItem one document of multi term vectors, either ID or Doc (artificial document) is required,
+// Fields and Options override the shared ones of MultiTermVectors
+type TermVectorsItem struct {
+	Index   string
+	ID      string
+	Routing string
+	Fields  []string
+	Options *TermVectorsOptions
+}
+
+type MultiTermVectorsResponse struct {
+	Docs []TermVectorsResponse `json:"docs"`
+}
+
+func (m *MultiTermVectorsResponse) FromJSON(bytes []byte) error {
+	return FromJSONImplDefault(bytes, m)
+}
+
+func termVectorsOperation(item TermVectorsItem, fields []string, opts *TermVectorsOptions) (map[string]any, error) {
+	if item.Options != nil {
+		opts = item.Options
+	}
+	if opts == nil {
+		opts = &TermVectorsOptions{}
+	}
+	if len(item.Fields) > 0 {
+		fields = item.Fields
+	}
+	// the typed MTermVectorsOperation always sends `_id`, which is wrong for artificial documents
+	op := map[string]any{"_index": item.Index}
+	if opts.Doc != nil {
+		op["doc"] = opts.Doc
+	} else if item.ID != "" {
+		op["_id"] = item.ID
+	} else {
+		return nil, fmt.Errorf("term vectors item needs either a document id or an artificial document, index=%s", item.Index)
+	}
+	if len(fields) > 0 {
+		op["fields"] = fields
+	}
+	if opts.Filter != nil {
+		op["filter"] = opts.Filter
+	}
+	if len(opts.PerFieldAnalyzer) > 0 {
+		op["per_field_analyzer"] = opts.PerFieldAnalyzer
+	}
+	if opts.TermStatistics {
+		op["term_statistics"] = true
+	}
+	if opts.FieldStatistics != nil {
+		op["field_statistics"] = *opts.FieldStatistics
+	}
+	if opts.Positions != nil {
+		op["positions"] = *opts.Positions
+	}
+	if opts.Offsets != nil {
+		op["offsets"] = *opts.Offsets
+	}
+	if opts.Payloads != nil {
+		op["payloads"] = *opts.Payloads
+	}
+	if item.Routing != "" {
+		op["routing"] = item.Routing
+	}
+	return op, nil
+}
+
+// MultiTermVectors term vectors of multiple documents in one request, results are in request order,
+// fields and tvOpts are shared by all items unless the item overrides them
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/docs-multi-termvectors.html
+// supported options: WithPreference, WithRealtime,
+// WithRouting is the default routing of the items without their own Routing
+func (es *ElasticsearchEx) MultiTermVectors(ctx context.Context, items []TermVectorsItem, fields []string, tvOpts *TermVectorsOptions, opts ...RequestOption) (*MultiTermVectorsResponse, error) {
+	docs := make([]map[string]any, 0, len(items))
+	for _, item := range items {
+		op, err := termVectorsOperation(item, fields, tvOpts)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, op)
+	}
+	body, err := json.Marshal(map[string]any{"docs": docs})
+	if err != nil {
+		return nil, err
+	}
+
+	o := newRequestOptions(opts)
+	req := applyRouting(es.Core.Mtermvectors().Raw(body), o)
+	if o.preference != "" {
+		req.Preference(o.preference)
+	}
+	if o.realtime != nil {
+		req.Realtime(*o.realtime)
+	}
+
+	var rsp MultiTermVectorsResponse
+	err = doGetResponse[ErrGeneric](ctx, req, &rsp)
+	return &rsp, err
+}
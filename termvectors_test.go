@@ -0,0 +1,70 @@
+package elastic_wrapper
+
+import (
+	"context"
+	"testing"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/refresh"
+)
+
+func TestTermVectors(t *testing.T) {
+	es := newClient(t)
+	ctx := context.Background()
+
+	demoIndex := "test_term_vectors"
+
+	t.Cleanup(func() {
+		deleted, err := es.IndexDelete(context.Background(), demoIndex)
+		if err != nil {
+			t.Fatalf("delete index failed, err=%v", err)
+		}
+		if !deleted {
+			t.Fatalf("delete index failed, deleted=%v", deleted)
+		}
+	})
+
+	rsp, err := es.IndexCreateSimple(ctx, demoIndex, &types.TypeMapping{
+		Properties: map[string]types.Property{
+			"content": types.NewTextProperty(),
+		},
+	})
+	if err != nil {
+		t.Fatalf("create simple index failed, err=%v", err)
+	}
+	t.Logf("rsp=%+v", rsp)
+
+	_, err = es.DocCreateRefresh(ctx, demoIndex, "1", map[string]any{"content": "the quick fox jumps over the lazy dog"}, refresh.True)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tvRsp, err := es.TermVectors(ctx, demoIndex, "1", []string{"content"}, &TermVectorsOptions{TermStatistics: true}, WithRealtime(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("tvRsp=%+v", tvRsp)
+	the := tvRsp.TermVectors["content"].Terms["the"]
+	if the.TermFreq != 2 || the.DocFreq == nil || *the.DocFreq != 1 || len(the.Tokens) != 2 {
+		t.Fatalf("the=%+v", the)
+	}
+	if top := tvRsp.TopTerms("content", 1); len(top) != 1 || top[0] != "the" {
+		t.Fatalf("top=%v", top)
+	}
+
+	// artificial document
+	mtvRsp, err := es.MultiTermVectors(ctx, []TermVectorsItem{
+		{Index: demoIndex, ID: "1"},
+		{Index: demoIndex, Options: &TermVectorsOptions{Doc: map[string]any{"content": "lazy lazy cat"}}},
+	}, []string{"content"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("mtvRsp=%+v", mtvRsp)
+	if len(mtvRsp.Docs) != 2 {
+		t.Fatalf("len(mtvRsp.Docs)=%d", len(mtvRsp.Docs))
+	}
+	if lazy := mtvRsp.Docs[1].TermVectors["content"].Terms["lazy"]; lazy.TermFreq != 2 {
+		t.Fatalf("lazy=%+v", lazy)
+	}
+}
@@ -0,0 +1,85 @@
+package elastic_wrapper
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/core/update"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+
+	"github.com/ttys3/elastic-wrapper-go/scripts"
+)
+
+func TestUpdateFieldsScript(t *testing.T) {
+	es := newClient(t)
+
+	demoIndex := "test_update_fields_script"
+
+	t.Cleanup(func() {
+		deleted, err := es.IndexDelete(context.Background(), demoIndex)
+		if err != nil {
+			t.Fatalf("delete index failed, err=%v", err)
+		}
+		if !deleted {
+			t.Fatalf("delete index failed, deleted=%v", deleted)
+		}
+	})
+
+	rsp, err := es.IndexCreateSimple(context.Background(), demoIndex, &types.TypeMapping{})
+	if err != nil {
+		t.Fatalf("create simple index failed, err=%v", err)
+	}
+	t.Logf("rsp=%+v", rsp)
+
+	ok, err := NewUpdateFieldsScript().InitScript(context.Background(), es)
+	if err != nil || !ok {
+		t.Fatalf("init script failed, ok=%v err=%v", ok, err)
+	}
+
+	docRsp, err := es.DocCreateSimple(context.Background(), demoIndex, "1", map[string]any{
+		"tags":  []string{"a", "b", "c"},
+		"price": 10,
+		"old":   "value",
+		"meta":  map[string]any{"x": 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("docRsp=%+v", docRsp)
+
+	fields := scripts.Set("user.name", "tom").
+		Incr("user.visits", 1).
+		AddToSet("tags", "c", "d").
+		Pull("tags", "a").
+		Min("price", 8).
+		Max("price", 5).
+		Mul("price", 2).
+		SetIfAbsent("user.name", "jerry").
+		Rename("old", "user.old").
+		MapMerge("meta", map[string]any{"y": 2}).
+		Unset("missing.field")
+
+	upRsp, err := es.DocUpdate(context.Background(), demoIndex, "1", &update.Request{Script: ParamScript(fields)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("upRsp=%+v", upRsp)
+
+	var docGetRsp DocGetResponse[map[string]any]
+	err = es.DocGet(context.Background(), demoIndex, "1", &docGetRsp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("docGetRsp=%+v", docGetRsp)
+
+	expected := map[string]any{
+		"tags":  []any{"b", "c", "d"},
+		"price": float64(16),
+		"meta":  map[string]any{"x": float64(1), "y": float64(2)},
+		"user":  map[string]any{"name": "tom", "visits": float64(1), "old": "value"},
+	}
+	if !reflect.DeepEqual(docGetRsp.Source, expected) {
+		t.Fatalf("got %+v, expected %+v", docGetRsp.Source, expected)
+	}
+}
@@ -0,0 +1,48 @@
+package elastic_wrapper
+
+import (
+	"context"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/core/update"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"google.golang.org/protobuf/proto"
+)
+
+// DocUpsert merge the partial document into the existing document, or index it as a new document if not exists
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/docs-update.html#doc_as_upsert
+// the response Result is one of created, updated or noop
+func (es *ElasticsearchEx) DocUpsert(ctx context.Context, index, id string, partial any, opts ...RequestOption) (*DocUpdateResponse, error) {
+	updateReq := update.NewRequest()
+	updateReq.Doc = partial
+	updateReq.DocAsUpsert = proto.Bool(true)
+	return es.DocUpdate(ctx, index, id, updateReq, opts...)
+}
+
+// DocUpsertWithScript run the script if the document exists, otherwise index upsertDoc as a new document.
+// If upsertDoc is nil, it is a scripted upsert: the script runs whether or not the document exists,
+// and starts with an empty ctx._source when the document is missing.
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/docs-update.html#scripted_upsert
+func (es *ElasticsearchEx) DocUpsertWithScript(ctx context.Context, index, id string, script *types.Script, upsertDoc any, opts ...RequestOption) (*DocUpdateResponse, error) {
+	updateReq := update.NewRequest()
+	updateReq.Script = script
+	if upsertDoc == nil {
+		updateReq.ScriptedUpsert = proto.Bool(true)
+		updateReq.Upsert = map[string]any{}
+	} else {
+		updateReq.Upsert = upsertDoc
+	}
+	return es.DocUpdate(ctx, index, id, updateReq, opts...)
+}
+
+// DocUpsertTyped typed variant of DocUpsert
+func DocUpsertTyped[T any](ctx context.Context, es *ElasticsearchEx, index, id string, partial *T, opts ...RequestOption) (*DocUpdateResponse, error) {
+	return es.DocUpsert(ctx, index, id, partial, opts...)
+}
+
+// DocUpsertWithScriptTyped typed variant of DocUpsertWithScript, a nil upsertDoc means scripted upsert
+func DocUpsertWithScriptTyped[T any](ctx context.Context, es *ElasticsearchEx, index, id string, script *types.Script, upsertDoc *T, opts ...RequestOption) (*DocUpdateResponse, error) {
+	if upsertDoc == nil {
+		return es.DocUpsertWithScript(ctx, index, id, script, nil, opts...)
+	}
+	return es.DocUpsertWithScript(ctx, index, id, script, upsertDoc, opts...)
+}
@@ -0,0 +1,95 @@
+package elastic_wrapper
+
+import (
+	"context"
+	"testing"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+)
+
+func TestDocUpsert(t *testing.T) {
+	es := newClient(t)
+
+	demoIndex := "test_doc_upsert"
+
+	t.Cleanup(func() {
+		deleted, err := es.IndexDelete(context.Background(), demoIndex)
+		if err != nil {
+			t.Fatalf("delete index failed, err=%v", err)
+		}
+		if !deleted {
+			t.Fatalf("delete index failed, deleted=%v", deleted)
+		}
+	})
+
+	rsp, err := es.IndexCreateSimple(context.Background(), demoIndex, &types.TypeMapping{
+		Properties: map[string]types.Property{
+			"content": types.NewKeywordProperty(),
+			"count":   types.NewIntegerNumberProperty(),
+		},
+	})
+	if err != nil {
+		t.Fatalf("create simple index failed, err=%v", err)
+	}
+	t.Logf("rsp=%+v", rsp)
+
+	type DemoDoc struct {
+		Content string `json:"content,omitempty"`
+		Count   int    `json:"count,omitempty"`
+	}
+
+	upRsp, err := DocUpsertTyped(context.Background(), es, demoIndex, "1", &DemoDoc{Content: "hello"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if upRsp.Result != "created" {
+		t.Fatalf("upsert missing document should create, upRsp=%+v", upRsp)
+	}
+
+	upRsp, err = es.DocUpsert(context.Background(), demoIndex, "1", map[string]any{"content": "world"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if upRsp.Result != "updated" || upRsp.Version != 2 {
+		t.Fatalf("upsert existing document should update, upRsp=%+v", upRsp)
+	}
+
+	upRsp, err = es.DocUpsert(context.Background(), demoIndex, "1", map[string]any{"content": "world"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if upRsp.Result != "noop" {
+		t.Fatalf("upsert with the same content should be noop, upRsp=%+v", upRsp)
+	}
+
+	script := types.NewInlineScript()
+	script.Source = "ctx._source.count = (ctx._source.count == null ? 0 : ctx._source.count) + params.n"
+	script.Params = map[string]any{"n": 2}
+	var theScript types.Script = script
+
+	// scripted upsert
+	upRsp, err = DocUpsertWithScriptTyped[DemoDoc](context.Background(), es, demoIndex, "2", &theScript, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if upRsp.Result != "created" {
+		t.Fatalf("scripted upsert missing document should create, upRsp=%+v", upRsp)
+	}
+
+	upRsp, err = es.DocUpsertWithScript(context.Background(), demoIndex, "2", &theScript, &DemoDoc{Count: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if upRsp.Result != "updated" {
+		t.Fatalf("upsert existing document should run the script, upRsp=%+v", upRsp)
+	}
+
+	var docGetRsp DocGetResponse[DemoDoc]
+	err = es.DocGet(context.Background(), demoIndex, "2", &docGetRsp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if docGetRsp.Source.Count != 4 {
+		t.Fatalf("docGetRsp.Source.Count=%d", docGetRsp.Source.Count)
+	}
+}